@@ -0,0 +1,115 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/joho/godotenv"
+	"github.com/thebigear/database"
+	"github.com/thebigear/models"
+	"github.com/thebigear/twitterear"
+	"github.com/tuvistavie/structomap"
+)
+
+func init() {
+	database.Connect()
+	database.EnsureIndexes()
+	// Use snake case in all serializers
+	structomap.SetDefaultCase(structomap.SnakeCase)
+	err := godotenv.Load()
+	if err != nil {
+		log.Fatal("Error loading .env file", err)
+	}
+}
+
+func main() {
+	config := flag.String("config", "campaigns.json", "JSON or YAML (.yaml, .yml) campaign file")
+	only := flag.String("campaign", "", "Comma separated campaigns to run, empty for all")
+	interval := flag.Duration("interval", 10*time.Minute, "Time between runner passes")
+	once := flag.Bool("once", false, "Run due campaigns once and exit")
+	force := flag.Bool("force", false, "Run campaigns whether they are due or not on the first pass")
+	replay := flag.String("replay", "", "Comma separated JSONL recordings to replay instead of calling Twitter")
+	record := flag.String("record", "", "JSONL file to record Twitter responses to")
+
+	flag.Parse()
+
+	campaigns, err := twitterear.LoadCampaigns(*config)
+	if err != nil {
+		log.Fatal("Error loading campaigns ", err)
+	}
+
+	selected := campaigns.Campaigns
+	if *only != "" {
+		selected = nil
+		for _, id := range twitterear.SplitKeywords(*only) {
+			campaign := campaigns.Get(id)
+			if campaign == nil {
+				log.Fatalf("Unknown campaign %q", id)
+			}
+			selected = append(selected, campaign)
+		}
+	}
+
+	fmt.Println("config:", *config)
+	fmt.Println("campaigns:", len(selected))
+	fmt.Println("interval:", *interval)
+
+	var source twitterear.TweetSource
+	if *replay != "" {
+		source, err = twitterear.NewReplaySource(twitterear.SplitKeywords(*replay)...)
+		if err != nil {
+			log.Fatal("Error loading recordings ", err)
+		}
+	} else {
+		source = twitterear.NewLiveSource(twitterear.NewTwitterClient())
+	}
+
+	if *record != "" {
+		recorder, err := twitterear.NewRecordingSource(source, *record)
+		if err != nil {
+			log.Fatal("Error opening recording ", err)
+		}
+		defer recorder.Close()
+		source = recorder
+	}
+
+	collectors := map[string]*twitterear.Collector{}
+	for _, campaign := range selected {
+		collector, err := campaign.NewCollector(source)
+		if err != nil {
+			log.Fatalf("Error creating collector of campaign %q: %v", campaign.ID, err)
+		}
+		collectors[campaign.ID] = collector
+	}
+
+	// Forcing only applies to the first pass, later ones follow the schedules
+	forced := *force
+	for {
+		for _, campaign := range selected {
+			if campaign.Disabled {
+				continue
+			}
+
+			// Replays run every campaign once, whatever its schedule
+			last, _ := models.GetLastCollectionRun(campaign.ID)
+			if !forced && *replay == "" && !campaign.Due(last, time.Now()) {
+				continue
+			}
+
+			fmt.Println("Running campaign ", campaign.ID)
+			campaign.Run(collectors[campaign.ID], *replay != "")
+		}
+
+		if *once || *replay != "" {
+			break
+		}
+		forced = false
+		time.Sleep(*interval)
+	}
+
+	for endpoint, budget := range twitterear.Limiter.Budgets() {
+		fmt.Printf("Rate limit %s: %d/%d left, resets at %s\n", endpoint, budget.Remaining, budget.Limit, budget.Reset.Format(time.RFC3339))
+	}
+}
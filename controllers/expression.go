@@ -3,6 +3,8 @@ package controllers
 import (
 	"fmt"
 	"net/http"
+	"regexp"
+	"strconv"
 
 	"github.com/labstack/echo"
 	"github.com/thebigear/database"
@@ -79,6 +81,25 @@ func ListExpressions(c echo.Context) error {
 		query["owner"] = regexQuery
 	}
 
+	if c.QueryParam("label") != "" {
+		nameQuery := database.Query{}
+		nameQuery["$regex"] = "^" + regexp.QuoteMeta(c.QueryParam("label")) + "$"
+		nameQuery["$options"] = "i"
+
+		labelQuery := database.Query{}
+		labelQuery["name"] = nameQuery
+
+		if c.QueryParam("min_confidence") != "" {
+			minConfidence, err := strconv.ParseFloat(c.QueryParam("min_confidence"), 64)
+			if err != nil {
+				return echo.NewHTTPError(http.StatusBadRequest)
+			}
+			labelQuery["confidence"] = database.Query{"$gte": minConfidence}
+		}
+
+		query["labels"] = database.Query{"$elemMatch": labelQuery}
+	}
+
 	expressions, err := models.ListExpressions(query, paginationParams)
 	if err != nil {
 		return err
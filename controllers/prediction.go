@@ -0,0 +1,56 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo"
+	"github.com/thebigear/dataset"
+	"github.com/thebigear/engagement"
+	"github.com/thebigear/models"
+	"github.com/thebigear/twitterear"
+)
+
+// PredictionConfidence is the confidence of predicted intervals
+const PredictionConfidence = 0.9
+
+// MaxDraftMedia is the most media a tweet can carry
+const MaxDraftMedia = 4
+
+// PredictionModels holds the model drafts are scored with, predictions are
+// unavailable while it has none
+var PredictionModels *engagement.Registry
+
+// CreatePrediction predicts the total interaction of a draft tweet
+func CreatePrediction(c echo.Context) error {
+	var model *engagement.Model
+	if PredictionModels != nil {
+		model = PredictionModels.Model()
+	}
+	if model == nil {
+		return echo.NewHTTPError(http.StatusServiceUnavailable, "no engagement model is loaded")
+	}
+
+	draft := &models.Draft{}
+	if err := c.Bind(draft); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest)
+	}
+	if strings.TrimSpace(draft.Text) == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "text is required")
+	}
+	if draft.MediaCount < 0 || draft.MediaCount > MaxDraftMedia {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("media_count must be between 0 and %d", MaxDraftMedia))
+	}
+
+	// Drafts are cleaned like the collector cleans tweets
+	expression := twitterear.DraftExpression(draft, twitterear.CleanPolicyFromEnv())
+	prediction := model.PredictInterval(dataset.Values(expression), PredictionConfidence)
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"prediction": prediction,
+		"clean_text": expression.CleanText,
+		"features":   expression.Features.Values,
+		"analysis":   expression.Analysis,
+	})
+}
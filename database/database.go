@@ -118,6 +118,46 @@ func EnsureIndexes() {
 		Sparse:     true,
 	}
 	Mongo.EnsureIndex("expressions", index)
+
+	snapshotIndex := mgo.Index{
+		Key:        []string{"post_id", "sampled_at"},
+		Unique:     false,
+		DropDups:   false,
+		Background: true,
+	}
+	Mongo.EnsureIndex("snapshots", snapshotIndex)
+
+	migrateSearchCheckpoints()
+
+	checkpointIndex := mgo.Index{
+		Key:        []string{"key", "lang"},
+		Unique:     true,
+		DropDups:   false,
+		Background: true,
+	}
+	Mongo.EnsureIndex("search_checkpoints", checkpointIndex)
+
+	modelVersionIndex := mgo.Index{
+		Key:        []string{"version"},
+		Unique:     true,
+		DropDups:   false,
+		Background: true,
+	}
+	Mongo.EnsureIndex("model_versions", modelVersionIndex)
+}
+
+// migrateSearchCheckpoints moves checkpoints saved when they were only
+// keyed by search key, which always searched English, to the key and
+// language index
+func migrateSearchCheckpoints() {
+	withoutLang := Query{}
+	withoutLang["lang"] = Query{"$exists": false}
+	if _, err := Mongo.UpdateAll("search_checkpoints", withoutLang, Query{"$set": Query{"lang": "en"}}); err != nil {
+		fmt.Println("Error migrating search checkpoints ", err)
+	}
+
+	// Fails once the index is gone, which is the state we want
+	Mongo.DropIndexName("search_checkpoints", "key_1")
 }
 
 // // CloneSession provides echo MiddlewareFunc that clones session for each request
@@ -142,6 +182,18 @@ func (db *MongoConn) FindOne(collection string, query Query, result interface{})
 		One(result)
 }
 
+// FindOneSelect returns the first document matching with criteria in
+// sortBy order, with only the fields of selector when it is not nil
+func (db *MongoConn) FindOneSelect(collection string, query Query, selector Query, sortBy string, result interface{}) error {
+	return db.Session.
+		DB(db.DialInfo.Database).
+		C(collection).
+		Find(query).
+		Select(selector).
+		Sort(sortBy).
+		One(result)
+}
+
 // FindAll returns all documents matching with criteria
 func (db *MongoConn) FindAll(collection string, query Query, result interface{}, pagination *PaginationParams) error {
 	queryResult := db.Session.
@@ -298,3 +350,11 @@ func (db *MongoConn) EnsureIndex(collection string, index mgo.Index) error {
 		C(collection).
 		EnsureIndex(index)
 }
+
+// DropIndexName drops the index with given name
+func (db *MongoConn) DropIndexName(collection string, name string) error {
+	return db.Session.
+		DB(db.DialInfo.Database).
+		C(collection).
+		DropIndexName(name)
+}
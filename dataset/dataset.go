@@ -0,0 +1,276 @@
+// Package dataset flattens expressions into rows of features and targets
+// for training engagement models, and splits them deterministically.
+package dataset
+
+import (
+	"fmt"
+	"hash/fnv"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/thebigear/database"
+	"github.com/thebigear/features"
+	"github.com/thebigear/models"
+)
+
+// Split names
+const (
+	SplitTrain      = "train"
+	SplitValidation = "validation"
+	SplitTest       = "test"
+)
+
+// Expression fields exported next to the extracted features
+const (
+	Polarity           = "polarity"
+	Positive           = "positive"
+	Negative           = "negative"
+	Neutral            = "neutral"
+	Followers          = "followers"
+	Following          = "following"
+	PostCount          = "post_count"
+	LastTenInteraction = "last_ten_interaction"
+	IsVerified         = "is_verified"
+	HasAttachment      = "has_attachment"
+)
+
+// Columns lists every feature column of a row in a fixed order, the
+// libsvm indices of features are their positions here plus one
+var Columns = append(append([]string{}, features.Names...),
+	Polarity, Positive, Negative, Neutral,
+	Followers, Following, PostCount, LastTenInteraction, IsVerified, HasAttachment,
+)
+
+// Row is the flattened form of an expression. Features missing on the
+// expression are missing from the row too.
+type Row struct {
+	PostID   int64              `json:"post_id"`
+	Split    string             `json:"split"`
+	Features map[string]float64 `json:"features"`
+	// Target is the total interaction and LogTarget its log1p
+	Target    float64 `json:"target"`
+	LogTarget float64 `json:"log_target"`
+	// ObservationAge is how many hours after posting the target was
+	// observed, nil for expressions collected before it was stored or with
+	// an unknown posting time
+	ObservationAge *float64 `json:"observation_age_hours,omitempty"`
+}
+
+// Ratios are the shares of the train, validation and test splits
+type Ratios struct {
+	Train      float64
+	Validation float64
+	Test       float64
+}
+
+// DefaultRatios splits 80% for training, 10% for validation and 10% for
+// testing
+var DefaultRatios = Ratios{Train: 0.8, Validation: 0.1, Test: 0.1}
+
+// ParseRatios parses ratios like "0.8,0.1,0.1", they are scaled to add up
+// to one
+func ParseRatios(value string) (Ratios, error) {
+	parts := strings.Split(value, ",")
+	if len(parts) != 3 {
+		return Ratios{}, fmt.Errorf("ratios need train, validation and test shares, got %q", value)
+	}
+
+	var shares [3]float64
+	total := 0.0
+	for i, part := range parts {
+		share, err := strconv.ParseFloat(strings.TrimSpace(part), 64)
+		if err != nil || share < 0 {
+			return Ratios{}, fmt.Errorf("invalid share %q", part)
+		}
+		shares[i] = share
+		total += share
+	}
+	if total == 0 {
+		return Ratios{}, fmt.Errorf("ratios %q add up to zero", value)
+	}
+
+	return Ratios{
+		Train:      shares[0] / total,
+		Validation: shares[1] / total,
+		Test:       shares[2] / total,
+	}, nil
+}
+
+// SplitOf assigns postID to a split by its hash, so an expression always
+// lands in the same split for the same ratios
+func SplitOf(postID int64, ratios Ratios) string {
+	hash := fnv.New64a()
+	hash.Write([]byte(strconv.FormatInt(postID, 10)))
+	bucket := float64(hash.Sum64()%10000) / 10000
+
+	switch {
+	case bucket < ratios.Train:
+		return SplitTrain
+	case bucket < ratios.Train+ratios.Validation:
+		return SplitValidation
+	}
+	return SplitTest
+}
+
+// NewRow flattens expression into a row of given split. Expressions
+// without an interaction count have no target and are reported as not ok.
+func NewRow(expression *models.Expression, ratios Ratios) (Row, bool) {
+	if expression.TotalInteraction == nil {
+		return Row{}, false
+	}
+
+	target := float64(*expression.TotalInteraction)
+	row := Row{
+		PostID:    expression.PostID,
+		Split:     SplitOf(expression.PostID, ratios),
+		Features:  Values(expression),
+		Target:    target,
+		LogTarget: math.Log1p(target),
+	}
+	if expression.Interactions != nil {
+		if age, known := expression.Interactions.Age(); known {
+			hours := age.Hours()
+			row.ObservationAge = &hours
+		}
+	}
+	return row, true
+}
+
+// Values flattens the features of expression into the named values of
+// Columns. Features computed by an older extractor are computed again so
+// every expression has the same schema.
+func Values(expression *models.Expression) map[string]float64 {
+	extracted := expression.Features
+	if !features.IsCurrent(expression) {
+		extracted = features.Extract(expression)
+	}
+
+	values := map[string]float64{}
+	for name, value := range extracted.Values {
+		values[name] = value
+	}
+
+	if analysis := expression.Analysis; analysis != nil {
+		values[Polarity] = analysis.Polarity
+		values[Positive] = analysis.Positive
+		values[Negative] = analysis.Negative
+		values[Neutral] = analysis.Neutral
+	}
+
+	setInt(values, Followers, expression.Followers)
+	setInt(values, Following, expression.Following)
+	setInt(values, PostCount, expression.PostCount)
+	// Before author histories were stored the sum counted later tweets
+	// and the tweet itself, it is only trusted next to a history
+	if expression.AuthorHistory != nil {
+		setInt(values, LastTenInteraction, expression.LastTenInteraction)
+	}
+	setBool(values, IsVerified, expression.IsVerified)
+	setBool(values, HasAttachment, expression.HasAttachment)
+
+	return values
+}
+
+// Each calls fn with every expression matching query, loading them batch
+// at a time in insertion order, and stops at the first error fn returns
+func Each(query database.Query, batch int, fn func(*models.Expression) error) error {
+	paginationParams := database.NewPaginationParams()
+	paginationParams.SortBy = "_id"
+	paginationParams.Limit = batch
+
+	for {
+		expressions, err := models.ListExpressions(query, paginationParams)
+		if err != nil {
+			return err
+		}
+		if len(*expressions) == 0 {
+			return nil
+		}
+
+		for i := range *expressions {
+			if err := fn(&(*expressions)[i]); err != nil {
+				return err
+			}
+		}
+		paginationParams.Page++
+	}
+}
+
+// Backfill calls update with every expression matching query, batch at a
+// time in insertion order, and stores it. Pages start after the last
+// expression read, so expressions leaving the query once updated do not
+// shift them. It returns the number of expressions updated.
+func Backfill(query database.Query, batch int, update func(*models.Expression)) (int, error) {
+	paginationParams := database.NewPaginationParams()
+	paginationParams.SortBy = "_id"
+	paginationParams.Limit = batch
+
+	paged := database.Query{}
+	for key, value := range query {
+		paged[key] = value
+	}
+
+	updated := 0
+	for {
+		expressions, err := models.ListExpressions(paged, paginationParams)
+		if err != nil {
+			return updated, err
+		}
+		if len(*expressions) == 0 {
+			return updated, nil
+		}
+
+		for i := range *expressions {
+			expression := &(*expressions)[i]
+			update(expression)
+			if _, err := expression.Update(); err != nil {
+				return updated, fmt.Errorf("expression %s: %v", expression.URLToken, err)
+			}
+			updated++
+		}
+		paged["_id"] = database.Query{"$gt": (*expressions)[len(*expressions)-1].ID}
+	}
+}
+
+// setInt sets the named value when field is known
+func setInt(values map[string]float64, name string, field *int) {
+	if field != nil {
+		values[name] = float64(*field)
+	}
+}
+
+// setBool sets the named value to 1 or 0 when field is known
+func setBool(values map[string]float64, name string, field *bool) {
+	if field == nil {
+		return
+	}
+	if *field {
+		values[name] = 1
+	} else {
+		values[name] = 0
+	}
+}
+
+// Load returns the rows of expressions matching query, only the ones of
+// split unless it is empty
+func Load(query database.Query, ratios Ratios, split string) ([]Row, error) {
+	var rows []Row
+	err := Each(query, 500, func(expression *models.Expression) error {
+		row, ok := NewRow(expression, ratios)
+		if ok && (split == "" || row.Split == split) {
+			rows = append(rows, row)
+		}
+		return nil
+	})
+	return rows, err
+}
+
+// BySplit groups rows by their split
+func BySplit(rows []Row) map[string][]Row {
+	splits := map[string][]Row{}
+	for _, row := range rows {
+		splits[row.Split] = append(splits[row.Split], row)
+	}
+	return splits
+}
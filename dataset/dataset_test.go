@@ -0,0 +1,79 @@
+package dataset
+
+import (
+	"math"
+	"testing"
+)
+
+func TestParseRatios(t *testing.T) {
+	tests := []struct {
+		value string
+		want  Ratios
+		err   bool
+	}{
+		{value: "0.8,0.1,0.1", want: Ratios{Train: 0.8, Validation: 0.1, Test: 0.1}},
+		{value: " 8, 1 ,1", want: Ratios{Train: 0.8, Validation: 0.1, Test: 0.1}},
+		{value: "1,0,1", want: Ratios{Train: 0.5, Validation: 0, Test: 0.5}},
+		{value: "0.8,0.2", err: true},
+		{value: "0.8,x,0.1", err: true},
+		{value: "0.8,-0.1,0.1", err: true},
+		{value: "0,0,0", err: true},
+	}
+
+	for _, test := range tests {
+		ratios, err := ParseRatios(test.value)
+		if (err != nil) != test.err {
+			t.Errorf("ParseRatios(%q): err = %v, want an error %v", test.value, err, test.err)
+			continue
+		}
+		if math.Abs(ratios.Train-test.want.Train) > 1e-9 || math.Abs(ratios.Validation-test.want.Validation) > 1e-9 || math.Abs(ratios.Test-test.want.Test) > 1e-9 {
+			t.Errorf("ParseRatios(%q) = %+v, want %+v", test.value, ratios, test.want)
+		}
+	}
+}
+
+func TestSplitOf(t *testing.T) {
+	// The buckets of these post ids are pinned, a change of hash would move
+	// expressions between splits of earlier exports and trained models
+	tests := []struct {
+		postID int64
+		ratios Ratios
+		want   string
+	}{
+		// Bucket 0.0628
+		{postID: 1000, ratios: DefaultRatios, want: SplitTrain},
+		// Bucket 0.7868
+		{postID: 1, ratios: DefaultRatios, want: SplitTrain},
+		// Bucket 0.8691
+		{postID: 42, ratios: DefaultRatios, want: SplitValidation},
+		// Bucket 0.9657
+		{postID: 6, ratios: DefaultRatios, want: SplitTest},
+		// Bucket 0.4552
+		{postID: 1049876543210987654, ratios: DefaultRatios, want: SplitTrain},
+		{postID: 1049876543210987654, ratios: Ratios{Train: 0.4, Validation: 0.1, Test: 0.5}, want: SplitValidation},
+		{postID: 1049876543210987654, ratios: Ratios{Train: 0.4, Validation: 0, Test: 0.6}, want: SplitTest},
+	}
+
+	for _, test := range tests {
+		for i := 0; i < 3; i++ {
+			if split := SplitOf(test.postID, test.ratios); split != test.want {
+				t.Errorf("SplitOf(%d, %+v) = %s, want %s", test.postID, test.ratios, split, test.want)
+				break
+			}
+		}
+	}
+}
+
+func TestSplitOfFollowsRatios(t *testing.T) {
+	counts := map[string]int{}
+	for postID := int64(1); postID <= 20000; postID++ {
+		counts[SplitOf(postID, DefaultRatios)]++
+	}
+
+	want := map[string]int{SplitTrain: 16000, SplitValidation: 2000, SplitTest: 2000}
+	for split, count := range want {
+		if math.Abs(float64(counts[split]-count)) > float64(count)/10 {
+			t.Errorf("%d %s rows of 20000, want about %d", counts[split], split, count)
+		}
+	}
+}
@@ -0,0 +1,133 @@
+package dataset
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// Export formats
+const (
+	FormatCSV    = "csv"
+	FormatJSONL  = "jsonl"
+	FormatLibSVM = "libsvm"
+)
+
+// Writer writes rows in an export format
+type Writer interface {
+	Write(row Row) error
+	// Flush writes whatever is buffered, it must be called once done
+	Flush() error
+}
+
+// CheckFormat returns an error for formats NewWriter does not know
+func CheckFormat(format string) error {
+	switch format {
+	case FormatCSV, FormatJSONL, FormatLibSVM:
+		return nil
+	}
+	return fmt.Errorf("unknown export format %q", format)
+}
+
+// NewWriter creates a writer of format on out. Libsvm lines are labeled
+// with the log target, the other formats carry both targets.
+func NewWriter(format string, out io.Writer) (Writer, error) {
+	switch format {
+	case FormatCSV:
+		return newCSVWriter(out), nil
+	case FormatJSONL:
+		buffered := bufio.NewWriter(out)
+		return &jsonlWriter{buffered: buffered, encoder: json.NewEncoder(buffered)}, nil
+	case FormatLibSVM:
+		return &libsvmWriter{buffered: bufio.NewWriter(out)}, nil
+	}
+
+	return nil, CheckFormat(format)
+}
+
+// csvWriter writes a header of Columns and leaves missing features empty
+type csvWriter struct {
+	csv           *csv.Writer
+	headerWritten bool
+}
+
+func newCSVWriter(out io.Writer) *csvWriter {
+	return &csvWriter{csv: csv.NewWriter(out)}
+}
+
+func (w *csvWriter) Write(row Row) error {
+	if !w.headerWritten {
+		header := append([]string{"post_id", "split"}, Columns...)
+		header = append(header, "target", "log_target", "observation_age_hours")
+		if err := w.csv.Write(header); err != nil {
+			return err
+		}
+		w.headerWritten = true
+	}
+
+	record := []string{strconv.FormatInt(row.PostID, 10), row.Split}
+	for _, column := range Columns {
+		if value, ok := row.Features[column]; ok {
+			record = append(record, formatFloat(value))
+		} else {
+			record = append(record, "")
+		}
+	}
+	record = append(record, formatFloat(row.Target), formatFloat(row.LogTarget))
+	if row.ObservationAge != nil {
+		record = append(record, formatFloat(*row.ObservationAge))
+	} else {
+		record = append(record, "")
+	}
+
+	return w.csv.Write(record)
+}
+
+func (w *csvWriter) Flush() error {
+	w.csv.Flush()
+	return w.csv.Error()
+}
+
+// jsonlWriter writes a row per line
+type jsonlWriter struct {
+	buffered *bufio.Writer
+	encoder  *json.Encoder
+}
+
+func (w *jsonlWriter) Write(row Row) error {
+	return w.encoder.Encode(row)
+}
+
+func (w *jsonlWriter) Flush() error {
+	return w.buffered.Flush()
+}
+
+// libsvmWriter writes sparse lines of the log target followed by the non
+// zero features as index:value, indices being positions in Columns plus one
+type libsvmWriter struct {
+	buffered *bufio.Writer
+}
+
+func (w *libsvmWriter) Write(row Row) error {
+	line := formatFloat(row.LogTarget)
+	for i, column := range Columns {
+		if value, ok := row.Features[column]; ok && value != 0 {
+			line += " " + strconv.Itoa(i+1) + ":" + formatFloat(value)
+		}
+	}
+
+	_, err := w.buffered.WriteString(line + "\n")
+	return err
+}
+
+func (w *libsvmWriter) Flush() error {
+	return w.buffered.Flush()
+}
+
+// formatFloat formats value as short as it can be read back
+func formatFloat(value float64) string {
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
@@ -0,0 +1,129 @@
+package dataset
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// testRows are a row with a few features and an observation age and one
+// with neither
+func testRows() []Row {
+	age := 72.5
+	return []Row{
+		{
+			PostID:         7,
+			Split:          SplitTrain,
+			Features:       map[string]float64{Followers: 1200, IsVerified: 0, HasAttachment: 1},
+			Target:         15,
+			LogTarget:      2.5,
+			ObservationAge: &age,
+		},
+		{PostID: 8, Split: SplitTest, Features: map[string]float64{}, Target: 0, LogTarget: 0},
+	}
+}
+
+// writeRows writes rows in format and returns the output
+func writeRows(t *testing.T, format string, rows []Row) string {
+	var out bytes.Buffer
+	writer, err := NewWriter(format, &out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, row := range rows {
+		if err := writer.Write(row); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := writer.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	return out.String()
+}
+
+// column is the libsvm index of name
+func column(name string) string {
+	for i, column := range Columns {
+		if column == name {
+			return strconv.Itoa(i + 1)
+		}
+	}
+	return ""
+}
+
+func TestCSVWriter(t *testing.T) {
+	lines := strings.Split(strings.TrimSuffix(writeRows(t, FormatCSV, testRows()), "\n"), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("%d lines, want a header and 2 rows", len(lines))
+	}
+
+	header := strings.Split(lines[0], ",")
+	wantHeader := append(append([]string{"post_id", "split"}, Columns...), "target", "log_target", "observation_age_hours")
+	if !reflect.DeepEqual(header, wantHeader) {
+		t.Errorf("header = %v, want %v", header, wantHeader)
+	}
+
+	values := map[string]string{}
+	for i, value := range strings.Split(lines[1], ",") {
+		values[header[i]] = value
+	}
+	want := map[string]string{
+		"post_id": "7", "split": "train", Followers: "1200", IsVerified: "0", HasAttachment: "1",
+		Following: "", "target": "15", "log_target": "2.5", "observation_age_hours": "72.5",
+	}
+	for name, value := range want {
+		if values[name] != value {
+			t.Errorf("%s = %q, want %q", name, values[name], value)
+		}
+	}
+
+	// Missing features and ages are left empty
+	if !strings.HasPrefix(lines[2], "8,test,,") || !strings.HasSuffix(lines[2], ",0,0,") {
+		t.Errorf("row without features = %q", lines[2])
+	}
+}
+
+func TestJSONLWriter(t *testing.T) {
+	lines := strings.Split(strings.TrimSuffix(writeRows(t, FormatJSONL, testRows()), "\n"), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("%d lines, want 2", len(lines))
+	}
+
+	for i, row := range testRows() {
+		var read Row
+		if err := json.Unmarshal([]byte(lines[i]), &read); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(read, row) {
+			t.Errorf("line %d reads back as %+v, want %+v", i+1, read, row)
+		}
+	}
+	if strings.Contains(lines[1], "observation_age_hours") {
+		t.Errorf("unknown age exported: %s", lines[1])
+	}
+}
+
+func TestLibSVMWriter(t *testing.T) {
+	// Indices are sorted positions in Columns, zero features are left out
+	want := "2.5 " + column(Followers) + ":1200 " + column(HasAttachment) + ":1\n0\n"
+	if out := writeRows(t, FormatLibSVM, testRows()); out != want {
+		t.Errorf("libsvm output = %q, want %q", out, want)
+	}
+}
+
+func TestCheckFormat(t *testing.T) {
+	for _, format := range []string{FormatCSV, FormatJSONL, FormatLibSVM} {
+		if err := CheckFormat(format); err != nil {
+			t.Errorf("CheckFormat(%q): %v", format, err)
+		}
+	}
+	if err := CheckFormat("parquet"); err == nil {
+		t.Errorf("CheckFormat accepted parquet")
+	}
+	if _, err := NewWriter("parquet", &bytes.Buffer{}); err == nil {
+		t.Errorf("NewWriter accepted parquet")
+	}
+}
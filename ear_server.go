@@ -1,14 +1,19 @@
 package main
 
 import (
+	"fmt"
 	"log"
 	"net/http"
+	"time"
 
 	"github.com/joho/godotenv"
 	_ "github.com/joho/godotenv/autoload"
 	"github.com/labstack/echo"
 	"github.com/thebigear/controllers"
 	"github.com/thebigear/database"
+	"github.com/thebigear/engagement"
+	"github.com/thebigear/twitterear"
+	"github.com/thebigear/utils"
 	"github.com/tuvistavie/structomap"
 )
 
@@ -18,8 +23,8 @@ func init() {
 	// Use snake case in all serializers
 	structomap.SetDefaultCase(structomap.SnakeCase)
 
-	// Init the ear
-	//go twitterear.TwitterEarConnect()
+	// Init the ear, it only listens when EAR_TRACK is set
+	go twitterear.TwitterEarConnect()
 }
 
 func main() {
@@ -27,6 +32,15 @@ func main() {
 	if err != nil {
 		log.Fatal("Error loading .env file")
 	}
+
+	// Predictions use the active model version, reloaded when it changes
+	registry := engagement.NewRegistry(utils.GetEnvOrDefault("ENGAGEMENT_MODEL", "engagement_models/gbt.json"))
+	if err := registry.Reload(); err != nil {
+		fmt.Println("Predictions are unavailable until a model is activated ", err)
+	}
+	controllers.PredictionModels = registry
+	go registry.Watch(utils.GetEnvDurationOrDefault("MODEL_RELOAD_INTERVAL", time.Minute), nil)
+
 	e := echo.New()
 	e.GET("/", func(c echo.Context) error {
 		return c.String(http.StatusOK, "Hello, World!")
@@ -36,5 +50,7 @@ func main() {
 	e.GET("/expressions", controllers.ListExpressions)
 	e.PUT("/expressions/:id", controllers.UpdateExpression)
 	e.DELETE("/expressions/:id", controllers.DeleteExpression)
+
+	e.POST("/predictions", controllers.CreatePrediction)
 	e.Logger.Fatal(e.Start(":1323"))
 }
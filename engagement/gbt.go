@@ -0,0 +1,195 @@
+package engagement
+
+import (
+	"sort"
+)
+
+// GBT is a gradient boosted ensemble of regression trees fitted on squared
+// error
+type GBT struct {
+	Base         float64 `json:"base"`
+	LearningRate float64 `json:"learning_rate"`
+	Trees        []Tree  `json:"trees"`
+}
+
+// Tree is a regression tree stored as a flat list of nodes, the root first
+type Tree struct {
+	Nodes []Node `json:"nodes"`
+}
+
+// Node is a split on a feature or, when Feature is -1, a leaf
+type Node struct {
+	Feature   int     `json:"feature"`
+	Threshold float64 `json:"threshold,omitempty"`
+	Left      int     `json:"left,omitempty"`
+	Right     int     `json:"right,omitempty"`
+	Value     float64 `json:"value,omitempty"`
+}
+
+// GBTParams control the size and pace of boosting
+type GBTParams struct {
+	Trees        int
+	Depth        int
+	LearningRate float64
+	MinLeaf      int
+}
+
+// FitGBT boosts trees fitting the residuals of targets on vectors
+func FitGBT(vectors [][]float64, targets []float64, params GBTParams) (*GBT, error) {
+	if len(vectors) == 0 {
+		return nil, ErrNoRows
+	}
+
+	gbt := &GBT{LearningRate: params.LearningRate}
+	for _, target := range targets {
+		gbt.Base += target
+	}
+	gbt.Base /= float64(len(targets))
+
+	predictions := make([]float64, len(targets))
+	for i := range predictions {
+		predictions[i] = gbt.Base
+	}
+
+	residuals := make([]float64, len(targets))
+	indices := make([]int, len(vectors))
+	for t := 0; t < params.Trees; t++ {
+		for i := range residuals {
+			residuals[i] = targets[i] - predictions[i]
+			indices[i] = i
+		}
+
+		builder := &treeBuilder{vectors: vectors, targets: residuals, params: params}
+		builder.grow(indices, 0)
+		tree := Tree{Nodes: builder.nodes}
+
+		for i, vector := range vectors {
+			predictions[i] += gbt.LearningRate * tree.Predict(vector)
+		}
+		gbt.Trees = append(gbt.Trees, tree)
+	}
+
+	return gbt, nil
+}
+
+// Predict predicts the target of vector
+func (gbt *GBT) Predict(vector []float64) float64 {
+	prediction := gbt.Base
+	for _, tree := range gbt.Trees {
+		prediction += gbt.LearningRate * tree.Predict(vector)
+	}
+	return prediction
+}
+
+// Predict walks the tree down to the leaf of vector
+func (tree Tree) Predict(vector []float64) float64 {
+	node := tree.Nodes[0]
+	for node.Feature >= 0 {
+		if vector[node.Feature] <= node.Threshold {
+			node = tree.Nodes[node.Left]
+		} else {
+			node = tree.Nodes[node.Right]
+		}
+	}
+	return node.Value
+}
+
+// treeBuilder grows a single tree on the residuals of the ensemble
+type treeBuilder struct {
+	vectors [][]float64
+	targets []float64
+	params  GBTParams
+	nodes   []Node
+}
+
+// grow adds the subtree of indices at depth and returns its position
+func (b *treeBuilder) grow(indices []int, depth int) int {
+	position := len(b.nodes)
+	b.nodes = append(b.nodes, Node{Feature: -1, Value: b.mean(indices)})
+
+	if depth >= b.params.Depth || len(indices) < 2*b.params.MinLeaf {
+		return position
+	}
+
+	feature, threshold, ok := b.bestSplit(indices)
+	if !ok {
+		return position
+	}
+
+	var left, right []int
+	for _, index := range indices {
+		if b.vectors[index][feature] <= threshold {
+			left = append(left, index)
+		} else {
+			right = append(right, index)
+		}
+	}
+
+	b.nodes[position].Feature = feature
+	b.nodes[position].Threshold = threshold
+	b.nodes[position].Value = 0
+	leftPosition := b.grow(left, depth+1)
+	rightPosition := b.grow(right, depth+1)
+	b.nodes[position].Left = leftPosition
+	b.nodes[position].Right = rightPosition
+
+	return position
+}
+
+// bestSplit finds the split of indices reducing squared error the most
+// while leaving at least MinLeaf rows on each side
+func (b *treeBuilder) bestSplit(indices []int) (int, float64, bool) {
+	total := 0.0
+	for _, index := range indices {
+		total += b.targets[index]
+	}
+	n := float64(len(indices))
+
+	bestGain := 1e-12
+	bestFeature := -1
+	bestThreshold := 0.0
+
+	sorted := make([]int, len(indices))
+	for feature := range b.vectors[indices[0]] {
+		copy(sorted, indices)
+		sort.Slice(sorted, func(i, j int) bool {
+			return b.vectors[sorted[i]][feature] < b.vectors[sorted[j]][feature]
+		})
+
+		leftSum := 0.0
+		for i := 0; i < len(sorted)-1; i++ {
+			leftSum += b.targets[sorted[i]]
+
+			current := b.vectors[sorted[i]][feature]
+			next := b.vectors[sorted[i+1]][feature]
+			leftCount := float64(i + 1)
+			if current == next || i+1 < b.params.MinLeaf || len(sorted)-i-1 < b.params.MinLeaf {
+				continue
+			}
+
+			// The squared error a split removes only depends on the sum
+			// and count of targets on each side
+			rightSum := total - leftSum
+			gain := leftSum*leftSum/leftCount + rightSum*rightSum/(n-leftCount) - total*total/n
+			if gain > bestGain {
+				bestGain = gain
+				bestFeature = feature
+				bestThreshold = (current + next) / 2
+			}
+		}
+	}
+
+	return bestFeature, bestThreshold, bestFeature >= 0
+}
+
+// mean is the average target of indices
+func (b *treeBuilder) mean(indices []int) float64 {
+	if len(indices) == 0 {
+		return 0
+	}
+	sum := 0.0
+	for _, index := range indices {
+		sum += b.targets[index]
+	}
+	return sum / float64(len(indices))
+}
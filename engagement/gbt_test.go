@@ -0,0 +1,65 @@
+package engagement
+
+import "testing"
+
+func TestFitGBTStep(t *testing.T) {
+	// y steps from 0 to 10 between x = 4 and 5, the second feature is noise
+	var vectors [][]float64
+	var targets []float64
+	for x := 0; x < 10; x++ {
+		vectors = append(vectors, []float64{float64(x), float64(x % 3)})
+		target := 0.0
+		if x >= 5 {
+			target = 10
+		}
+		targets = append(targets, target)
+	}
+
+	gbt, err := FitGBT(vectors, targets, GBTParams{Trees: 1, Depth: 1, LearningRate: 1, MinLeaf: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !near(gbt.Base, 5) {
+		t.Errorf("base = %v, want 5", gbt.Base)
+	}
+	root := gbt.Trees[0].Nodes[0]
+	if root.Feature != 0 || !near(root.Threshold, 4.5) {
+		t.Errorf("root splits feature %d at %v, want feature 0 at 4.5", root.Feature, root.Threshold)
+	}
+	for i, vector := range vectors {
+		if prediction := gbt.Predict(vector); !near(prediction, targets[i]) {
+			t.Errorf("prediction at %v = %v, want %v", vector, prediction, targets[i])
+		}
+	}
+}
+
+func TestFitGBTShrinksAndStops(t *testing.T) {
+	vectors := [][]float64{{0}, {1}, {2}, {3}}
+	targets := []float64{0, 0, 8, 8}
+
+	tests := []struct {
+		name   string
+		params GBTParams
+		// want are the predictions at 0 and 3
+		want [2]float64
+	}{
+		// Each tree removes half of what is left of the residuals
+		{name: "two halved trees", params: GBTParams{Trees: 2, Depth: 1, LearningRate: 0.5, MinLeaf: 1}, want: [2]float64{1, 7}},
+		// Too few rows to leave MinLeaf on each side, the tree is a leaf
+		{name: "min leaf", params: GBTParams{Trees: 1, Depth: 3, LearningRate: 1, MinLeaf: 3}, want: [2]float64{4, 4}},
+		{name: "no depth", params: GBTParams{Trees: 3, Depth: 0, LearningRate: 1, MinLeaf: 1}, want: [2]float64{4, 4}},
+	}
+
+	for _, test := range tests {
+		gbt, err := FitGBT(vectors, targets, test.params)
+		if err != nil {
+			t.Fatalf("%s: %v", test.name, err)
+		}
+
+		low, high := gbt.Predict(vectors[0]), gbt.Predict(vectors[3])
+		if !near(low, test.want[0]) || !near(high, test.want[1]) {
+			t.Errorf("%s: predictions %v and %v, want %v", test.name, low, high, test.want)
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package engagement
+
+import (
+	"math"
+	"sort"
+
+	"github.com/thebigear/models"
+)
+
+// Evaluate compares predicted values with observed ones
+func Evaluate(predicted, observed []float64) models.Metrics {
+	metrics := models.Metrics{Count: len(observed)}
+	if len(observed) == 0 {
+		return metrics
+	}
+
+	mean := 0.0
+	for _, value := range observed {
+		mean += value
+	}
+	mean /= float64(len(observed))
+
+	absolute := 0.0
+	squared := 0.0
+	total := 0.0
+	for i, value := range observed {
+		residual := value - predicted[i]
+		absolute += math.Abs(residual)
+		squared += residual * residual
+		total += (value - mean) * (value - mean)
+	}
+
+	metrics.MAE = absolute / float64(len(observed))
+	metrics.RMSE = math.Sqrt(squared / float64(len(observed)))
+	if total > 0 {
+		metrics.R2 = 1 - squared/total
+	}
+	metrics.Spearman = pearson(ranks(predicted), ranks(observed))
+
+	return metrics
+}
+
+// ranks returns the ranks of values starting at one, ties share the average
+// of their ranks
+func ranks(values []float64) []float64 {
+	order := make([]int, len(values))
+	for i := range order {
+		order[i] = i
+	}
+	sort.Slice(order, func(a, b int) bool { return values[order[a]] < values[order[b]] })
+
+	result := make([]float64, len(values))
+	for start := 0; start < len(order); {
+		end := start + 1
+		for end < len(order) && values[order[end]] == values[order[start]] {
+			end++
+		}
+
+		rank := float64(start+end+1) / 2
+		for _, index := range order[start:end] {
+			result[index] = rank
+		}
+		start = end
+	}
+	return result
+}
+
+// pearson is the correlation of x and y, zero when either is constant
+func pearson(x, y []float64) float64 {
+	n := float64(len(x))
+	if n == 0 {
+		return 0
+	}
+
+	meanX, meanY := 0.0, 0.0
+	for i := range x {
+		meanX += x[i]
+		meanY += y[i]
+	}
+	meanX /= n
+	meanY /= n
+
+	covariance, varianceX, varianceY := 0.0, 0.0, 0.0
+	for i := range x {
+		covariance += (x[i] - meanX) * (y[i] - meanY)
+		varianceX += (x[i] - meanX) * (x[i] - meanX)
+		varianceY += (y[i] - meanY) * (y[i] - meanY)
+	}
+
+	if varianceX == 0 || varianceY == 0 {
+		return 0
+	}
+	return covariance / math.Sqrt(varianceX*varianceY)
+}
@@ -0,0 +1,100 @@
+package engagement
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestRanks(t *testing.T) {
+	tests := []struct {
+		values []float64
+		want   []float64
+	}{
+		{values: []float64{30, 10, 20}, want: []float64{3, 1, 2}},
+		{values: []float64{10, 20, 20, 30}, want: []float64{1, 2.5, 2.5, 4}},
+		{values: []float64{5, 5, 5}, want: []float64{2, 2, 2}},
+		{values: []float64{}, want: []float64{}},
+	}
+
+	for _, test := range tests {
+		if got := ranks(test.values); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("ranks(%v) = %v, want %v", test.values, got, test.want)
+		}
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	tests := []struct {
+		name      string
+		predicted []float64
+		observed  []float64
+		mae       float64
+		rmse      float64
+		r2        float64
+		spearman  float64
+	}{
+		{
+			name:      "perfect",
+			predicted: []float64{1, 2, 3},
+			observed:  []float64{1, 2, 3},
+			r2:        1,
+			spearman:  1,
+		},
+		{
+			// Residuals 0, 0 and 2 around an observed mean of 8/3
+			name:      "one miss",
+			predicted: []float64{1, 2, 3},
+			observed:  []float64{1, 2, 5},
+			mae:       2.0 / 3,
+			rmse:      math.Sqrt(4.0 / 3),
+			r2:        1 - 36.0/78,
+			spearman:  1,
+		},
+		{
+			// Tied predictions share rank 2.5, the correlation of ranks
+			// is 4.5/√(4.5·5)
+			name:      "ties",
+			predicted: []float64{1, 2, 2, 3},
+			observed:  []float64{1, 2, 3, 4},
+			mae:       0.5,
+			rmse:      math.Sqrt(0.5),
+			r2:        1 - 2.0/5,
+			spearman:  4.5 / math.Sqrt(22.5),
+		},
+		{
+			name:      "reversed",
+			predicted: []float64{3, 2, 1},
+			observed:  []float64{1, 2, 3},
+			mae:       4.0 / 3,
+			rmse:      math.Sqrt(8.0 / 3),
+			r2:        -3,
+			spearman:  -1,
+		},
+		{
+			// A constant prediction has no rank correlation
+			name:      "constant",
+			predicted: []float64{2, 2, 2},
+			observed:  []float64{1, 2, 3},
+			mae:       2.0 / 3,
+			rmse:      math.Sqrt(2.0 / 3),
+			r2:        0,
+			spearman:  0,
+		},
+	}
+
+	for _, test := range tests {
+		metrics := Evaluate(test.predicted, test.observed)
+		if metrics.Count != len(test.observed) {
+			t.Errorf("%s: count = %d, want %d", test.name, metrics.Count, len(test.observed))
+		}
+		if !near(metrics.MAE, test.mae) || !near(metrics.RMSE, test.rmse) || !near(metrics.R2, test.r2) || !near(metrics.Spearman, test.spearman) {
+			t.Errorf("%s: MAE %v, RMSE %v, R² %v, Spearman %v, want %v, %v, %v, %v", test.name,
+				metrics.MAE, metrics.RMSE, metrics.R2, metrics.Spearman, test.mae, test.rmse, test.r2, test.spearman)
+		}
+	}
+
+	if metrics := Evaluate(nil, nil); metrics.Count != 0 || metrics.RMSE != 0 {
+		t.Errorf("empty evaluation = %+v", metrics)
+	}
+}
@@ -0,0 +1,211 @@
+// Package engagement trains and runs the models predicting the total
+// interaction of expressions from their features.
+package engagement
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"time"
+
+	"github.com/thebigear/dataset"
+	"github.com/thebigear/features"
+	"github.com/thebigear/models"
+)
+
+// Model kinds
+const (
+	KindRidge = "ridge"
+	KindGBT   = "gbt"
+)
+
+// ErrNoRows is returned when there is nothing to train on
+var ErrNoRows = errors.New("no rows to train on")
+
+// Params are the hyperparameters of every model kind
+type Params struct {
+	Lambda float64
+	GBT    GBTParams
+}
+
+// DefaultParams are sensible starting hyperparameters
+var DefaultParams = Params{
+	Lambda: 1,
+	GBT: GBTParams{
+		Trees:        200,
+		Depth:        3,
+		LearningRate: 0.1,
+		MinLeaf:      10,
+	},
+}
+
+// Model predicts log1p of total interaction from the feature columns it
+// was trained with. It is saved as JSON along with its feature schema.
+type Model struct {
+	// Version names the model in the registry, its kind and training time
+	Version string `json:"version"`
+	Kind    string `json:"kind"`
+	// FeatureVersion is the extractor version the model was trained on
+	FeatureVersion int      `json:"feature_version"`
+	Columns        []string `json:"columns"`
+	// Fill holds the training mean of each column, used for missing
+	// features
+	Fill  []float64 `json:"fill"`
+	Ridge *Ridge    `json:"ridge,omitempty"`
+	GBT   *GBT      `json:"gbt,omitempty"`
+	// Metrics are measured on the validation split, on the log scale
+	Metrics   models.Metrics `json:"metrics"`
+	TrainedOn int            `json:"trained_on"`
+	TrainedAt time.Time      `json:"trained_at"`
+}
+
+// Train fits a model of kind on rows with the log target
+func Train(kind string, rows []dataset.Row, params Params) (*Model, error) {
+	if len(rows) == 0 {
+		return nil, ErrNoRows
+	}
+
+	trainedAt := time.Now()
+	model := &Model{
+		Version:        kind + "-" + trainedAt.UTC().Format("20060102T150405"),
+		Kind:           kind,
+		FeatureVersion: features.Version,
+		Columns:        dataset.Columns,
+		Fill:           fill(rows, dataset.Columns),
+		TrainedOn:      len(rows),
+		TrainedAt:      trainedAt,
+	}
+
+	vectors := make([][]float64, len(rows))
+	targets := make([]float64, len(rows))
+	for i, row := range rows {
+		vectors[i] = model.Vector(row.Features)
+		targets[i] = row.LogTarget
+	}
+
+	var err error
+	switch kind {
+	case KindRidge:
+		model.Ridge, err = FitRidge(vectors, targets, params.Lambda)
+	case KindGBT:
+		model.GBT, err = FitGBT(vectors, targets, params.GBT)
+	default:
+		err = fmt.Errorf("unknown model kind %q", kind)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return model, nil
+}
+
+// Vector lays values out in the columns of the model, filling the missing
+// ones
+func (model *Model) Vector(values map[string]float64) []float64 {
+	vector := make([]float64, len(model.Columns))
+	for j, column := range model.Columns {
+		if value, ok := values[column]; ok {
+			vector[j] = value
+		} else {
+			vector[j] = model.Fill[j]
+		}
+	}
+	return vector
+}
+
+// Predict predicts log1p of the total interaction of values
+func (model *Model) Predict(values map[string]float64) float64 {
+	vector := model.Vector(values)
+	if model.GBT != nil {
+		return model.GBT.Predict(vector)
+	}
+	return model.Ridge.Predict(vector)
+}
+
+// PredictInteraction predicts the total interaction of values
+func (model *Model) PredictInteraction(values map[string]float64) float64 {
+	return math.Max(0, math.Expm1(model.Predict(values)))
+}
+
+// PredictInterval predicts the total interaction of values along with the
+// interval it falls in with given confidence, assuming log errors are
+// normal with the validation RMSE as deviation
+func (model *Model) PredictInterval(values map[string]float64, confidence float64) models.Prediction {
+	predicted := model.Predict(values)
+	margin := math.Sqrt2 * math.Erfinv(confidence) * model.Metrics.RMSE
+
+	return models.Prediction{
+		Interaction: math.Max(0, math.Expm1(predicted)),
+		Lower:       math.Max(0, math.Expm1(predicted-margin)),
+		Upper:       math.Max(0, math.Expm1(predicted+margin)),
+		Confidence:  confidence,
+		Model:       model.Version,
+		PredictedAt: time.Now(),
+	}
+}
+
+// Evaluate measures the model on rows, on the log scale
+func (model *Model) Evaluate(rows []dataset.Row) models.Metrics {
+	predicted := make([]float64, len(rows))
+	observed := make([]float64, len(rows))
+	for i, row := range rows {
+		predicted[i] = model.Predict(row.Features)
+		observed[i] = row.LogTarget
+	}
+	return Evaluate(predicted, observed)
+}
+
+// CheckSchema returns an error when the model was trained on features of
+// another extractor version
+func (model *Model) CheckSchema() error {
+	if model.FeatureVersion != features.Version {
+		return fmt.Errorf("model was trained on feature version %d, extractor is at %d", model.FeatureVersion, features.Version)
+	}
+	return nil
+}
+
+// Save writes the model to path as JSON
+func (model *Model) Save(path string) error {
+	data, err := json.Marshal(model)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, data, 0644)
+}
+
+// LoadModel reads a model saved at path
+func LoadModel(path string) (*Model, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	model := &Model{}
+	if err := json.Unmarshal(data, model); err != nil {
+		return nil, err
+	}
+	if model.Ridge == nil && model.GBT == nil {
+		return nil, fmt.Errorf("model at %s has no %s or %s fit", path, KindRidge, KindGBT)
+	}
+	return model, nil
+}
+
+// fill returns the mean of each column over the rows that have it
+func fill(rows []dataset.Row, columns []string) []float64 {
+	means := make([]float64, len(columns))
+	for j, column := range columns {
+		count := 0
+		for _, row := range rows {
+			if value, ok := row.Features[column]; ok {
+				means[j] += value
+				count++
+			}
+		}
+		if count > 0 {
+			means[j] /= float64(count)
+		}
+	}
+	return means
+}
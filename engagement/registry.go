@@ -0,0 +1,160 @@
+package engagement
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/thebigear/database"
+	"github.com/thebigear/dataset"
+	"github.com/thebigear/models"
+)
+
+// Register records model, saved at path, as a new version of the registry
+// along with the dataset query it was trained on. The model itself is
+// stored too, so it can be loaded without the file.
+func Register(model *Model, path string, datasetQuery string) (*models.ModelVersion, error) {
+	artifact, err := json.Marshal(model)
+	if err != nil {
+		return nil, err
+	}
+
+	version := &models.ModelVersion{
+		Version:        model.Version,
+		Kind:           model.Kind,
+		DatasetQuery:   datasetQuery,
+		FeatureVersion: model.FeatureVersion,
+		Metrics:        model.Metrics,
+		TrainedOn:      model.TrainedOn,
+		ArtifactPath:   path,
+		Artifact:       artifact,
+	}
+	return version.Create()
+}
+
+// LoadVersion loads the model of a registry version, from its stored
+// artifact or else from its artifact path
+func LoadVersion(version *models.ModelVersion) (*Model, error) {
+	var model *Model
+	if len(version.Artifact) > 0 {
+		model = &Model{}
+		if err := json.Unmarshal(version.Artifact, model); err != nil {
+			return nil, err
+		}
+	} else {
+		loaded, err := LoadModel(version.ArtifactPath)
+		if err != nil {
+			return nil, err
+		}
+		model = loaded
+	}
+
+	if err := model.CheckSchema(); err != nil {
+		return nil, err
+	}
+	return model, nil
+}
+
+// TrainingRatios returns the split ratios version was trained with, rows
+// of its train split were seen by the model
+func TrainingRatios(version *models.ModelVersion) (dataset.Ratios, error) {
+	var trained struct {
+		Ratios *dataset.Ratios `json:"ratios"`
+	}
+	if err := json.Unmarshal([]byte(version.DatasetQuery), &trained); err != nil {
+		return dataset.Ratios{}, err
+	}
+	if trained.Ratios == nil {
+		return dataset.Ratios{}, fmt.Errorf("model version %s has no split ratios", version.Version)
+	}
+	return *trained.Ratios, nil
+}
+
+// Registry keeps the active model version loaded and swaps it when another
+// version is activated
+type Registry struct {
+	// Fallback is the model file loaded while no version is active
+	Fallback string
+
+	mutex sync.RWMutex
+	model *Model
+}
+
+// NewRegistry creates a registry falling back to the model file at fallback
+func NewRegistry(fallback string) *Registry {
+	return &Registry{Fallback: fallback}
+}
+
+// Model returns the loaded model, nil when there is none
+func (registry *Registry) Model() *Model {
+	registry.mutex.RLock()
+	defer registry.mutex.RUnlock()
+
+	return registry.model
+}
+
+// Reload loads the active version unless it is the loaded one already.
+// The fallback file is only loaded when no version was ever active.
+func (registry *Registry) Reload() error {
+	current := registry.Model()
+
+	// Only the version is read on every tick, the artifact when it changed
+	activeVersion, err := models.GetActiveVersion()
+	if err != nil {
+		if current != nil || registry.Fallback == "" {
+			return nil
+		}
+
+		model, err := LoadModel(registry.Fallback)
+		if err == nil {
+			err = model.CheckSchema()
+		}
+		if err != nil {
+			return fmt.Errorf("no active model version and fallback %s failed: %v", registry.Fallback, err)
+		}
+		registry.set(model)
+		return nil
+	}
+
+	if current != nil && current.Version == activeVersion {
+		return nil
+	}
+
+	active, err := models.GetModelVersion(database.Query{"version": activeVersion})
+	if err != nil {
+		return fmt.Errorf("getting model version %s: %v", activeVersion, err)
+	}
+	model, err := LoadVersion(active)
+	if err != nil {
+		return fmt.Errorf("loading model version %s: %v", active.Version, err)
+	}
+	registry.set(model)
+	fmt.Println("Loaded model version ", model.Version)
+	return nil
+}
+
+// Watch reloads the active version every interval until stop is closed
+func (registry *Registry) Watch(interval time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			if err := registry.Reload(); err != nil {
+				fmt.Println("Error reloading model ", err)
+			}
+		}
+	}
+}
+
+// set swaps the loaded model
+func (registry *Registry) set(model *Model) {
+	registry.mutex.Lock()
+	defer registry.mutex.Unlock()
+
+	registry.model = model
+}
@@ -0,0 +1,57 @@
+package engagement
+
+import (
+	"math"
+
+	"github.com/thebigear/models"
+)
+
+// Outcome pairs a stored prediction with the interaction observed later
+type Outcome struct {
+	Prediction models.Prediction
+	Observed   float64
+}
+
+// Report compares the predictions of a model version with what happened
+type Report struct {
+	Version string
+	// Log metrics are on log1p of interactions, Interaction metrics on
+	// the counts themselves
+	Log         models.Metrics
+	Interaction models.Metrics
+	// Bias is the mean of predicted minus observed interactions
+	Bias float64
+	// Coverage is the share of observed interactions inside the predicted
+	// interval
+	Coverage float64
+}
+
+// NewReport builds the report of version over outcomes
+func NewReport(version string, outcomes []Outcome) Report {
+	report := Report{Version: version}
+	if len(outcomes) == 0 {
+		return report
+	}
+
+	predicted := make([]float64, len(outcomes))
+	observed := make([]float64, len(outcomes))
+	logPredicted := make([]float64, len(outcomes))
+	logObserved := make([]float64, len(outcomes))
+	covered := 0
+	for i, outcome := range outcomes {
+		predicted[i] = outcome.Prediction.Interaction
+		observed[i] = outcome.Observed
+		logPredicted[i] = math.Log1p(predicted[i])
+		logObserved[i] = math.Log1p(observed[i])
+
+		report.Bias += (predicted[i] - observed[i]) / float64(len(outcomes))
+		if outcome.Observed >= outcome.Prediction.Lower && outcome.Observed <= outcome.Prediction.Upper {
+			covered++
+		}
+	}
+
+	report.Log = Evaluate(logPredicted, logObserved)
+	report.Interaction = Evaluate(predicted, observed)
+	report.Coverage = float64(covered) / float64(len(outcomes))
+	return report
+}
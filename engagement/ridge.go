@@ -0,0 +1,134 @@
+package engagement
+
+import (
+	"errors"
+	"math"
+)
+
+// ErrSingular is returned when the normal equations have no single solution
+var ErrSingular = errors.New("singular system")
+
+// Ridge is a linear regression with an L2 penalty, fitted on standardized
+// features
+type Ridge struct {
+	Lambda    float64   `json:"lambda"`
+	Means     []float64 `json:"means"`
+	Scales    []float64 `json:"scales"`
+	Weights   []float64 `json:"weights"`
+	Intercept float64   `json:"intercept"`
+}
+
+// FitRidge fits a ridge regression of targets on vectors with penalty lambda
+func FitRidge(vectors [][]float64, targets []float64, lambda float64) (*Ridge, error) {
+	if len(vectors) == 0 {
+		return nil, ErrNoRows
+	}
+
+	width := len(vectors[0])
+	ridge := &Ridge{
+		Lambda: lambda,
+		Means:  make([]float64, width),
+		Scales: make([]float64, width),
+	}
+
+	n := float64(len(vectors))
+	for _, vector := range vectors {
+		for j, value := range vector {
+			ridge.Means[j] += value / n
+		}
+	}
+	for _, vector := range vectors {
+		for j, value := range vector {
+			ridge.Scales[j] += (value - ridge.Means[j]) * (value - ridge.Means[j]) / n
+		}
+	}
+	for j := range ridge.Scales {
+		ridge.Scales[j] = math.Sqrt(ridge.Scales[j])
+		if ridge.Scales[j] == 0 {
+			ridge.Scales[j] = 1
+		}
+	}
+
+	for _, target := range targets {
+		ridge.Intercept += target / n
+	}
+
+	// Solve (X'X + lambda I)w = X'y on centered data
+	gram := make([][]float64, width)
+	moment := make([]float64, width)
+	for j := range gram {
+		gram[j] = make([]float64, width)
+		gram[j][j] = lambda
+	}
+	for i, vector := range vectors {
+		standard := ridge.standardize(vector)
+		for j := range standard {
+			moment[j] += standard[j] * (targets[i] - ridge.Intercept)
+			for k := range standard {
+				gram[j][k] += standard[j] * standard[k]
+			}
+		}
+	}
+
+	weights, err := solve(gram, moment)
+	if err != nil {
+		return nil, err
+	}
+	ridge.Weights = weights
+
+	return ridge, nil
+}
+
+// Predict predicts the target of vector
+func (ridge *Ridge) Predict(vector []float64) float64 {
+	prediction := ridge.Intercept
+	for j, value := range ridge.standardize(vector) {
+		prediction += ridge.Weights[j] * value
+	}
+	return prediction
+}
+
+// standardize centers and scales vector with the training statistics
+func (ridge *Ridge) standardize(vector []float64) []float64 {
+	standard := make([]float64, len(vector))
+	for j, value := range vector {
+		standard[j] = (value - ridge.Means[j]) / ridge.Scales[j]
+	}
+	return standard
+}
+
+// solve solves a·x = b by Gaussian elimination with partial pivoting
+func solve(a [][]float64, b []float64) ([]float64, error) {
+	n := len(b)
+	for column := 0; column < n; column++ {
+		pivot := column
+		for row := column + 1; row < n; row++ {
+			if math.Abs(a[row][column]) > math.Abs(a[pivot][column]) {
+				pivot = row
+			}
+		}
+		if math.Abs(a[pivot][column]) < 1e-12 {
+			return nil, ErrSingular
+		}
+		a[column], a[pivot] = a[pivot], a[column]
+		b[column], b[pivot] = b[pivot], b[column]
+
+		for row := column + 1; row < n; row++ {
+			factor := a[row][column] / a[column][column]
+			for k := column; k < n; k++ {
+				a[row][k] -= factor * a[column][k]
+			}
+			b[row] -= factor * b[column]
+		}
+	}
+
+	x := make([]float64, n)
+	for row := n - 1; row >= 0; row-- {
+		sum := b[row]
+		for k := row + 1; k < n; k++ {
+			sum -= a[row][k] * x[k]
+		}
+		x[row] = sum / a[row][row]
+	}
+	return x, nil
+}
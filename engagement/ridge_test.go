@@ -0,0 +1,92 @@
+package engagement
+
+import (
+	"math"
+	"testing"
+)
+
+// near reports whether got is within 1e-9 of want
+func near(got, want float64) bool {
+	return math.Abs(got-want) < 1e-9
+}
+
+func TestSolve(t *testing.T) {
+	tests := []struct {
+		name string
+		a    [][]float64
+		b    []float64
+		want []float64
+		err  error
+	}{
+		{name: "diagonal", a: [][]float64{{2, 0}, {0, 4}}, b: []float64{2, 8}, want: []float64{1, 2}},
+		{name: "dense", a: [][]float64{{2, 1}, {1, 3}}, b: []float64{5, 10}, want: []float64{1, 3}},
+		// The first pivot is zero, rows have to be swapped
+		{name: "pivoting", a: [][]float64{{0, 1, 1}, {2, 1, 0}, {1, 0, 1}}, b: []float64{5, 4, 4}, want: []float64{1, 2, 3}},
+		{name: "singular", a: [][]float64{{1, 2}, {2, 4}}, b: []float64{3, 6}, err: ErrSingular},
+	}
+
+	for _, test := range tests {
+		x, err := solve(test.a, test.b)
+		if err != test.err {
+			t.Errorf("%s: err = %v, want %v", test.name, err, test.err)
+			continue
+		}
+		for i := range test.want {
+			if !near(x[i], test.want[i]) {
+				t.Errorf("%s: x = %v, want %v", test.name, x, test.want)
+				break
+			}
+		}
+	}
+}
+
+func TestFitRidge(t *testing.T) {
+	// y = 3x + 1 on x = 1..5: x has mean 3 and standard deviation √2, the
+	// standardized weight is 3√2·5/(5+λ)
+	vectors := [][]float64{{1}, {2}, {3}, {4}, {5}}
+	targets := []float64{4, 7, 10, 13, 16}
+
+	tests := []struct {
+		lambda     float64
+		weight     float64
+		prediction float64
+	}{
+		{lambda: 0, weight: 3 * math.Sqrt2, prediction: 19},
+		{lambda: 5, weight: 1.5 * math.Sqrt2, prediction: 14.5},
+	}
+
+	for _, test := range tests {
+		ridge, err := FitRidge(vectors, targets, test.lambda)
+		if err != nil {
+			t.Fatalf("lambda %v: %v", test.lambda, err)
+		}
+
+		if !near(ridge.Means[0], 3) || !near(ridge.Scales[0], math.Sqrt2) {
+			t.Errorf("lambda %v: mean %v and scale %v, want 3 and √2", test.lambda, ridge.Means[0], ridge.Scales[0])
+		}
+		if !near(ridge.Intercept, 10) || !near(ridge.Weights[0], test.weight) {
+			t.Errorf("lambda %v: intercept %v and weight %v, want 10 and %v", test.lambda, ridge.Intercept, ridge.Weights[0], test.weight)
+		}
+		if prediction := ridge.Predict([]float64{6}); !near(prediction, test.prediction) {
+			t.Errorf("lambda %v: prediction at 6 = %v, want %v", test.lambda, prediction, test.prediction)
+		}
+	}
+
+	// A constant feature keeps a unit scale, without a penalty it makes the
+	// system singular
+	constant := [][]float64{{1, 7}, {2, 7}, {3, 7}, {4, 7}, {5, 7}}
+	if _, err := FitRidge(constant, targets, 0); err != ErrSingular {
+		t.Errorf("unpenalized constant feature: err = %v, want %v", err, ErrSingular)
+	}
+	ridge, err := FitRidge(constant, targets, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !near(ridge.Scales[1], 1) || !near(ridge.Weights[1], 0) {
+		t.Errorf("constant feature scale %v and weight %v, want 1 and 0", ridge.Scales[1], ridge.Weights[1])
+	}
+
+	if _, err := FitRidge(nil, nil, 1); err != ErrNoRows {
+		t.Errorf("fitting no rows: err = %v, want %v", err, ErrNoRows)
+	}
+}
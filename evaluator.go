@@ -0,0 +1,94 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/joho/godotenv"
+	"github.com/thebigear/database"
+	"github.com/thebigear/dataset"
+	"github.com/thebigear/engagement"
+	"github.com/thebigear/models"
+	"github.com/tuvistavie/structomap"
+)
+
+func init() {
+	database.Connect()
+	database.EnsureIndexes()
+	// Use snake case in all serializers
+	structomap.SetDefaultCase(structomap.SnakeCase)
+	err := godotenv.Load()
+	if err != nil {
+		log.Fatal("Error loading .env file", err)
+	}
+}
+
+func main() {
+	paths := flag.String("model", "engagement_models/ridge.json,engagement_models/gbt.json", "Comma separated saved models")
+	split := flag.String("split", dataset.SplitTest, "Holdout split to evaluate on")
+	lang := flag.String("lang", "", "Only evaluate on expressions in this language")
+
+	flag.Parse()
+
+	query := database.Query{}
+	query["deleted_at"] = nil
+	query["total_interaction"] = database.Query{"$exists": true}
+	// Expressions collected before maturity was tagged were all mature
+	query["mature"] = database.Query{"$ne": false}
+	if *lang != "" {
+		query["lang"] = *lang
+	}
+
+	// Holdouts follow the ratios each model was trained with, other ratios
+	// would mix its training rows in
+	holdouts := map[dataset.Ratios][]dataset.Row{}
+
+	for _, path := range strings.Split(*paths, ",") {
+		model, err := engagement.LoadModel(path)
+		if err != nil {
+			fmt.Println("Error loading model ", path, err)
+			continue
+		}
+		if err := model.CheckSchema(); err != nil {
+			fmt.Println("Skipping ", path, err)
+			continue
+		}
+
+		version, err := models.GetModelVersion(database.Query{"version": model.Version})
+		if err != nil {
+			fmt.Println("Skipping unregistered ", path, err)
+			continue
+		}
+		ratios, err := engagement.TrainingRatios(version)
+		if err != nil {
+			fmt.Println("Skipping ", path, err)
+			continue
+		}
+
+		rows, loaded := holdouts[ratios]
+		if !loaded {
+			if rows, err = dataset.Load(query, ratios, *split); err != nil {
+				log.Fatal("Error loading rows ", err)
+			}
+			holdouts[ratios] = rows
+		}
+
+		logMetrics := model.Evaluate(rows)
+
+		predicted := make([]float64, len(rows))
+		observed := make([]float64, len(rows))
+		for i, row := range rows {
+			predicted[i] = model.PredictInteraction(row.Features)
+			observed[i] = row.Target
+		}
+		metrics := engagement.Evaluate(predicted, observed)
+
+		fmt.Printf("%s (%s), %d %s rows\n", path, model.Kind, len(rows), *split)
+		fmt.Printf("  log1p:        MAE %.4f RMSE %.4f R2 %.4f Spearman %.4f\n",
+			logMetrics.MAE, logMetrics.RMSE, logMetrics.R2, logMetrics.Spearman)
+		fmt.Printf("  interactions: MAE %.2f RMSE %.2f R2 %.4f\n",
+			metrics.MAE, metrics.RMSE, metrics.R2)
+	}
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"regexp"
+	"time"
+
+	"github.com/joho/godotenv"
+	"github.com/thebigear/database"
+	"github.com/thebigear/dataset"
+	"github.com/thebigear/models"
+	"github.com/tuvistavie/structomap"
+)
+
+func init() {
+	database.Connect()
+	database.EnsureIndexes()
+	// Use snake case in all serializers
+	structomap.SetDefaultCase(structomap.SnakeCase)
+	err := godotenv.Load()
+	if err != nil {
+		log.Fatal("Error loading .env file", err)
+	}
+}
+
+func main() {
+	format := flag.String("format", dataset.FormatCSV, "Output format: csv, jsonl or libsvm")
+	out := flag.String("out", "", "Output file (default dataset.<format>)")
+	split := flag.String("split", "", "Only export the train, validation or test split")
+	ratiosFlag := flag.String("ratios", "0.8,0.1,0.1", "Train, validation and test shares")
+	lang := flag.String("lang", "", "Only export expressions in this language")
+	owner := flag.String("owner", "", "Only export expressions of this owner id")
+	campaign := flag.String("campaign", "", "Only export expressions collected by this campaign")
+	label := flag.String("label", "", "Only export expressions with this image label")
+	since := flag.String("since", "", "Only export expressions collected on or after this date (2006-01-02)")
+	until := flag.String("until", "", "Only export expressions collected before this date (2006-01-02)")
+	minInteraction := flag.Int("min-interaction", 0, "Only export expressions with at least this total interaction")
+	immature := flag.Bool("immature", false, "Also export expressions whose interaction count is not mature yet")
+	batch := flag.Int("batch", 500, "Expressions loaded at a time")
+
+	flag.Parse()
+
+	ratios, err := dataset.ParseRatios(*ratiosFlag)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if *split != "" && *split != dataset.SplitTrain && *split != dataset.SplitValidation && *split != dataset.SplitTest {
+		log.Fatal("Unknown split ", *split)
+	}
+	// Check the format before creating the output it would be written to
+	if err := dataset.CheckFormat(*format); err != nil {
+		log.Fatal(err)
+	}
+	if *out == "" {
+		*out = "dataset." + *format
+	}
+
+	query := database.Query{}
+	query["deleted_at"] = nil
+	query["total_interaction"] = database.Query{"$gte": *minInteraction}
+	if !*immature {
+		// Expressions collected before maturity was tagged were all mature
+		query["mature"] = database.Query{"$ne": false}
+	}
+	if *lang != "" {
+		query["lang"] = *lang
+	}
+	if *owner != "" {
+		query["owner"] = *owner
+	}
+	if *campaign != "" {
+		query["campaign"] = *campaign
+	}
+	if *label != "" {
+		query["labels.name"] = database.Query{"$regex": "^" + regexp.QuoteMeta(*label) + "$", "$options": "i"}
+	}
+
+	createdAt := database.Query{}
+	if *since != "" {
+		date, err := time.Parse("2006-01-02", *since)
+		if err != nil {
+			log.Fatal("Invalid since date ", err)
+		}
+		createdAt["$gte"] = date
+	}
+	if *until != "" {
+		date, err := time.Parse("2006-01-02", *until)
+		if err != nil {
+			log.Fatal("Invalid until date ", err)
+		}
+		createdAt["$lt"] = date
+	}
+	if len(createdAt) > 0 {
+		query["created_at"] = createdAt
+	}
+
+	file, err := os.Create(*out)
+	if err != nil {
+		log.Fatal("Error creating output ", err)
+	}
+	defer file.Close()
+
+	writer, err := dataset.NewWriter(*format, file)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	counts := map[string]int{}
+	err = dataset.Each(query, *batch, func(expression *models.Expression) error {
+		row, ok := dataset.NewRow(expression, ratios)
+		if !ok || (*split != "" && row.Split != *split) {
+			return nil
+		}
+		counts[row.Split]++
+		return writer.Write(row)
+	})
+	if err != nil {
+		log.Fatal("Error exporting expressions ", err)
+	}
+	if err := writer.Flush(); err != nil {
+		log.Fatal("Error writing output ", err)
+	}
+
+	fmt.Println("Exported to", *out)
+	for _, name := range []string{dataset.SplitTrain, dataset.SplitValidation, dataset.SplitTest} {
+		fmt.Printf("%s: %d\n", name, counts[name])
+	}
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+
+	"github.com/joho/godotenv"
+	"github.com/thebigear/database"
+	"github.com/thebigear/dataset"
+	"github.com/thebigear/features"
+	"github.com/thebigear/models"
+	"github.com/tuvistavie/structomap"
+)
+
+func init() {
+	database.Connect()
+	database.EnsureIndexes()
+	// Use snake case in all serializers
+	structomap.SetDefaultCase(structomap.SnakeCase)
+	err := godotenv.Load()
+	if err != nil {
+		log.Fatal("Error loading .env file", err)
+	}
+}
+
+func main() {
+	all := flag.Bool("all", false, "Recompute features of every expression, not only outdated ones")
+	batch := flag.Int("batch", 500, "Expressions loaded at a time")
+
+	flag.Parse()
+
+	fmt.Println("version:", features.Version)
+	fmt.Println("all:", *all)
+
+	query := database.Query{}
+	query["deleted_at"] = nil
+	if !*all {
+		query["features.version"] = database.Query{"$ne": features.Version}
+	}
+
+	extracted, err := dataset.Backfill(query, *batch, func(expression *models.Expression) {
+		expression.Features = features.Extract(expression)
+	})
+	if err != nil {
+		log.Fatal("Error extracting features ", err)
+	}
+	fmt.Println("Features extracted: ", extracted)
+}
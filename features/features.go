@@ -0,0 +1,159 @@
+// Package features computes the versioned feature maps engagement models
+// are trained on. Features are derived from stored expression fields only,
+// so they can be recomputed whenever the extractor changes.
+package features
+
+import (
+	"html"
+	"regexp"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/thebigear/models"
+	"mvdan.cc/xurls"
+)
+
+// Version of the extractor, bump it whenever a feature is added, removed
+// or computed differently so stored maps get recomputed
+const Version = 2
+
+// Feature names
+const (
+	TextLength       = "text_length"
+	WordCount        = "word_count"
+	HashtagCount     = "hashtag_count"
+	MentionCount     = "mention_count"
+	URLCount         = "url_count"
+	QuestionMarks    = "question_marks"
+	ExclamationMarks = "exclamation_marks"
+	CapitalRatio     = "capital_ratio"
+	PostHour         = "post_hour"
+	PostWeekday      = "post_weekday"
+	FollowerRatio    = "follower_ratio"
+	AccountAgeDays   = "account_age_days"
+	MediaCount       = "media_count"
+	LabelCount       = "label_count"
+
+	// Interactions of the tweets the author posted before the expression
+	AuthorHistoryCount      = "author_history_count"
+	AuthorMeanInteraction   = "author_mean_interaction"
+	AuthorMedianInteraction = "author_median_interaction"
+	AuthorStdInteraction    = "author_std_interaction"
+	AuthorMaxInteraction    = "author_max_interaction"
+)
+
+// Names lists the features of Version in a fixed order
+var Names = []string{
+	TextLength,
+	WordCount,
+	HashtagCount,
+	MentionCount,
+	URLCount,
+	QuestionMarks,
+	ExclamationMarks,
+	CapitalRatio,
+	PostHour,
+	PostWeekday,
+	FollowerRatio,
+	AccountAgeDays,
+	MediaCount,
+	LabelCount,
+	AuthorHistoryCount,
+	AuthorMeanInteraction,
+	AuthorMedianInteraction,
+	AuthorStdInteraction,
+	AuthorMaxInteraction,
+}
+
+var (
+	// hashtagReg matches hashtags not glued to a preceding word
+	hashtagReg = regexp.MustCompile(`(?:^|[^\p{L}\p{N}_&])[#\x{FF03}][\p{L}\p{M}\p{N}_]+`)
+	// mentionReg matches user mentions not glued to a preceding word
+	mentionReg = regexp.MustCompile(`(?:^|[^\p{L}\p{N}_])[@\x{FF20}][A-Za-z0-9_]{1,15}`)
+)
+
+// Extract computes the features of expression. Features whose inputs are
+// missing, like the account age of expressions collected before it was
+// stored, are left out of the map.
+func Extract(expression *models.Expression) *models.Features {
+	values := map[string]float64{}
+	text := html.UnescapeString(expression.FullText)
+
+	values[TextLength] = float64(len([]rune(text)))
+	values[WordCount] = float64(len(strings.Fields(text)))
+	values[HashtagCount] = float64(len(hashtagReg.FindAllString(text, -1)))
+	values[MentionCount] = float64(len(mentionReg.FindAllString(text, -1)))
+	values[URLCount] = float64(len(xurls.Strict().FindAllString(text, -1)))
+	values[QuestionMarks] = float64(strings.Count(text, "?"))
+	values[ExclamationMarks] = float64(strings.Count(text, "!"))
+	values[CapitalRatio] = capitalRatio(text)
+
+	if !expression.PostedAt.IsZero() {
+		posted := expression.PostedAt.UTC()
+		values[PostHour] = float64(posted.Hour())
+		values[PostWeekday] = float64(posted.Weekday())
+
+		if !expression.OwnerCreatedAt.IsZero() {
+			values[AccountAgeDays] = posted.Sub(expression.OwnerCreatedAt).Hours() / 24
+		}
+	}
+
+	if expression.Followers != nil && expression.Following != nil {
+		following := *expression.Following
+		if following < 1 {
+			following = 1
+		}
+		values[FollowerRatio] = float64(*expression.Followers) / float64(following)
+	}
+
+	mediaCount := len(expression.Media)
+	if mediaCount == 0 && expression.MediaURL != "" {
+		// Expressions collected before media were stored apart
+		mediaCount = 1
+	}
+	values[MediaCount] = float64(mediaCount)
+	values[LabelCount] = float64(len(expression.Labels))
+
+	if history := expression.AuthorHistory; history != nil {
+		values[AuthorHistoryCount] = float64(history.Count)
+		if history.Count > 0 {
+			values[AuthorMeanInteraction] = history.Mean
+			values[AuthorMedianInteraction] = history.Median
+			values[AuthorStdInteraction] = history.Std
+			values[AuthorMaxInteraction] = history.Max
+		}
+	}
+
+	return &models.Features{
+		Version:     Version,
+		Values:      values,
+		ExtractedAt: time.Now(),
+	}
+}
+
+// IsCurrent reports whether the features of expression were computed by
+// this Version of the extractor
+func IsCurrent(expression *models.Expression) bool {
+	return expression.Features != nil && expression.Features.Version == Version
+}
+
+// capitalRatio is the share of upper case letters among the cased letters
+// of text, scripts without case do not count
+func capitalRatio(text string) float64 {
+	upper := 0
+	cased := 0
+	for _, r := range text {
+		if unicode.IsUpper(r) {
+			upper++
+			cased++
+		} else if unicode.IsLower(r) {
+			cased++
+		}
+	}
+
+	if cased == 0 {
+		return 0
+	}
+	return float64(upper) / float64(cased)
+}
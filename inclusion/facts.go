@@ -0,0 +1,95 @@
+package inclusion
+
+import (
+	"strings"
+
+	"github.com/thebigear/models"
+)
+
+// Fields rules can be written on
+const (
+	FieldTotalInteraction = "total_interaction"
+	FieldFavoriteCount    = "favorite_count"
+	FieldRetweetCount     = "retweet_count"
+	FieldFollowers        = "followers"
+	FieldFollowing        = "following"
+	FieldPostCount        = "post_count"
+	FieldVerified         = "verified"
+	FieldLang             = "lang"
+	FieldHasMedia         = "has_media"
+	FieldMediaCount       = "media_count"
+	FieldTextLength       = "text_length"
+	FieldWordCount        = "word_count"
+	FieldMature           = "mature"
+)
+
+// Kinds of field values
+const (
+	kindNumber = "number"
+	kindBool   = "boolean"
+	kindString = "string"
+)
+
+// fieldKinds gives the kind of every field
+var fieldKinds = map[string]string{
+	FieldTotalInteraction: kindNumber,
+	FieldFavoriteCount:    kindNumber,
+	FieldRetweetCount:     kindNumber,
+	FieldFollowers:        kindNumber,
+	FieldFollowing:        kindNumber,
+	FieldPostCount:        kindNumber,
+	FieldVerified:         kindBool,
+	FieldLang:             kindString,
+	FieldHasMedia:         kindBool,
+	FieldMediaCount:       kindNumber,
+	FieldTextLength:       kindNumber,
+	FieldWordCount:        kindNumber,
+	FieldMature:           kindBool,
+}
+
+// Facts returns the known field values of expression. Text length and word
+// count are measured on the clean text.
+func Facts(expression *models.Expression) map[string]interface{} {
+	facts := map[string]interface{}{
+		FieldLang:       expression.Lang,
+		FieldHasMedia:   len(expression.Media) > 0 || expression.MediaURL != "",
+		FieldMediaCount: float64(len(expression.Media)),
+		FieldTextLength: float64(len([]rune(expression.CleanText))),
+		FieldWordCount:  float64(len(strings.Fields(expression.CleanText))),
+	}
+
+	setInt(facts, FieldTotalInteraction, expression.TotalInteraction)
+	setInt(facts, FieldFollowers, expression.Followers)
+	setInt(facts, FieldFollowing, expression.Following)
+	setInt(facts, FieldPostCount, expression.PostCount)
+	if expression.IsVerified != nil {
+		facts[FieldVerified] = *expression.IsVerified
+	}
+	if expression.Mature != nil {
+		facts[FieldMature] = *expression.Mature
+	}
+	if interactions := expression.Interactions; interactions != nil {
+		facts[FieldFavoriteCount] = float64(interactions.FavoriteCount)
+		facts[FieldRetweetCount] = float64(interactions.RetweetCount)
+	}
+
+	return facts
+}
+
+// zeroOf is a value of kind to validate rules against
+func zeroOf(kind string) interface{} {
+	switch kind {
+	case kindNumber:
+		return 0.0
+	case kindBool:
+		return false
+	}
+	return ""
+}
+
+// setInt sets the named fact when field is known
+func setInt(facts map[string]interface{}, name string, field *int) {
+	if field != nil {
+		facts[name] = float64(*field)
+	}
+}
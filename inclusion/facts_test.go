@@ -0,0 +1,53 @@
+package inclusion
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/thebigear/models"
+)
+
+func TestFacts(t *testing.T) {
+	total := 15
+	followers := 1200
+	verified := true
+	mature := false
+	expression := &models.Expression{
+		Lang:             "en",
+		CleanText:        "sunny day út",
+		TotalInteraction: &total,
+		Followers:        &followers,
+		IsVerified:       &verified,
+		Mature:           &mature,
+		Interactions:     &models.Interactions{FavoriteCount: 12, RetweetCount: 3},
+		Media:            []models.Media{{Type: models.MediaTypePhoto}},
+	}
+
+	want := map[string]interface{}{
+		FieldLang:             "en",
+		FieldHasMedia:         true,
+		FieldMediaCount:       1.0,
+		FieldTextLength:       12.0,
+		FieldWordCount:        3.0,
+		FieldTotalInteraction: 15.0,
+		FieldFollowers:        1200.0,
+		FieldVerified:         true,
+		FieldMature:           false,
+		FieldFavoriteCount:    12.0,
+		FieldRetweetCount:     3.0,
+	}
+	if facts := Facts(expression); !reflect.DeepEqual(facts, want) {
+		t.Errorf("facts = %v, want %v", facts, want)
+	}
+
+	// Unknown counts and author fields are left out rather than zero
+	facts := Facts(&models.Expression{})
+	for _, field := range []string{FieldTotalInteraction, FieldFavoriteCount, FieldFollowers, FieldVerified, FieldMature} {
+		if value, known := facts[field]; known {
+			t.Errorf("unknown %s is %v", field, value)
+		}
+	}
+	if facts[FieldHasMedia] != false || facts[FieldTextLength] != 0.0 {
+		t.Errorf("facts of an empty expression = %v", facts)
+	}
+}
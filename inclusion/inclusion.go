@@ -0,0 +1,221 @@
+// Package inclusion decides which collected tweets become expressions with
+// declarative rules over their counts, author and content.
+package inclusion
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	"github.com/thebigear/models"
+)
+
+// Actions a rule takes on the expressions it matches
+const (
+	// ActionAccept accepts right away, skipping the rules after it
+	ActionAccept = "accept"
+	// ActionReject rejects right away, the rule name is the reason
+	ActionReject = "reject"
+	// ActionFlag accepts but tags the expression with the rule name
+	ActionFlag = "flag"
+)
+
+// Rule matches expressions whose Field compares to Value with Op, one of
+// ==, !=, <, <=, >, >=, in and not_in
+type Rule struct {
+	Name   string      `json:"name"`
+	Field  string      `json:"field"`
+	Op     string      `json:"op"`
+	Value  interface{} `json:"value"`
+	Action string      `json:"action"`
+}
+
+// RuleSet applies its rules in order, expressions no rule rejects are
+// accepted
+type RuleSet struct {
+	Rules []Rule `json:"rules"`
+	// Source is where the rules were loaded from
+	Source string `json:"-"`
+}
+
+// Decision is the outcome of a rule set for an expression
+type Decision struct {
+	Accepted bool
+	// Reason is the rule that rejected the expression
+	Reason string
+	// Flags are the rules that flagged an accepted expression
+	Flags []string
+}
+
+// DefaultRules are the inclusion rules of search collection runs
+var DefaultRules = &RuleSet{
+	Source: "default",
+	Rules: []Rule{
+		{Name: "empty_text", Field: FieldTextLength, Op: "==", Value: 0.0, Action: ActionReject},
+		{Name: "low_interaction", Field: FieldTotalInteraction, Op: "<", Value: 2.0, Action: ActionReject},
+		{Name: "few_favorites", Field: FieldFavoriteCount, Op: "<", Value: 3.0, Action: ActionReject},
+		{Name: "viral_outlier", Field: FieldTotalInteraction, Op: ">", Value: 5000.0, Action: ActionReject},
+	},
+}
+
+// StreamRules are the inclusion rules of streamed tweets, which are brand
+// new and have no interactions yet
+var StreamRules = &RuleSet{
+	Source: "stream",
+	Rules: []Rule{
+		{Name: "empty_text", Field: FieldTextLength, Op: "==", Value: 0.0, Action: ActionReject},
+	},
+}
+
+// FreshRules are the inclusion rules of searched tweets younger than the
+// maturity age, whose interactions are too early to judge
+var FreshRules = &RuleSet{
+	Source: "fresh",
+	Rules: []Rule{
+		{Name: "empty_text", Field: FieldTextLength, Op: "==", Value: 0.0, Action: ActionReject},
+	},
+}
+
+// LoadRules reads a JSON rule set from path
+func LoadRules(path string) (*RuleSet, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	set := &RuleSet{}
+	if err := json.Unmarshal(data, set); err != nil {
+		return nil, err
+	}
+	set.Source = path
+
+	if err := set.Validate(); err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	return set, nil
+}
+
+// Validate checks that every rule has a name, a known field, an operator
+// and a value fitting the field and a known action
+func (set *RuleSet) Validate() error {
+	for i, rule := range set.Rules {
+		if rule.Name == "" {
+			return fmt.Errorf("rule %d has no name", i+1)
+		}
+
+		kind, ok := fieldKinds[rule.Field]
+		if !ok {
+			return fmt.Errorf("rule %s: unknown field %q", rule.Name, rule.Field)
+		}
+
+		switch rule.Action {
+		case ActionAccept, ActionReject, ActionFlag:
+		default:
+			return fmt.Errorf("rule %s: unknown action %q", rule.Name, rule.Action)
+		}
+
+		if _, err := compare(kind, zeroOf(kind), rule.Op, rule.Value); err != nil {
+			return fmt.Errorf("rule %s: %v", rule.Name, err)
+		}
+	}
+	return nil
+}
+
+// Decide applies the rules to expression
+func (set *RuleSet) Decide(expression *models.Expression) Decision {
+	facts := Facts(expression)
+	decision := Decision{Accepted: true}
+
+	for _, rule := range set.Rules {
+		value, known := facts[rule.Field]
+		if !known {
+			// Rules never match what is unknown about an expression
+			continue
+		}
+
+		matched, err := compare(fieldKinds[rule.Field], value, rule.Op, rule.Value)
+		if err != nil || !matched {
+			continue
+		}
+
+		switch rule.Action {
+		case ActionAccept:
+			return decision
+		case ActionReject:
+			return Decision{Reason: rule.Name}
+		case ActionFlag:
+			decision.Flags = append(decision.Flags, rule.Name)
+		}
+	}
+
+	return decision
+}
+
+// compare compares the fact value of a field of kind with the rule value
+func compare(kind string, value interface{}, op string, ruleValue interface{}) (bool, error) {
+	if op == "in" || op == "not_in" {
+		list, ok := ruleValue.([]interface{})
+		if !ok {
+			return false, fmt.Errorf("%s needs a list", op)
+		}
+
+		found := false
+		for _, element := range list {
+			equal, err := compare(kind, value, "==", element)
+			if err != nil {
+				return false, err
+			}
+			found = found || equal
+		}
+		return found == (op == "in"), nil
+	}
+
+	switch kind {
+	case kindNumber:
+		number, ok := ruleValue.(float64)
+		if !ok {
+			return false, fmt.Errorf("%v is not a number", ruleValue)
+		}
+		fact := value.(float64)
+
+		switch op {
+		case "==":
+			return fact == number, nil
+		case "!=":
+			return fact != number, nil
+		case "<":
+			return fact < number, nil
+		case "<=":
+			return fact <= number, nil
+		case ">":
+			return fact > number, nil
+		case ">=":
+			return fact >= number, nil
+		}
+	case kindBool:
+		flag, ok := ruleValue.(bool)
+		if !ok {
+			return false, fmt.Errorf("%v is not a boolean", ruleValue)
+		}
+		switch op {
+		case "==":
+			return value.(bool) == flag, nil
+		case "!=":
+			return value.(bool) != flag, nil
+		}
+	case kindString:
+		text, ok := ruleValue.(string)
+		if !ok {
+			return false, fmt.Errorf("%v is not a string", ruleValue)
+		}
+		switch op {
+		case "==":
+			return strings.EqualFold(value.(string), text), nil
+		case "!=":
+			return !strings.EqualFold(value.(string), text), nil
+		}
+	}
+
+	return false, fmt.Errorf("operator %q does not apply to %s fields", op, kind)
+}
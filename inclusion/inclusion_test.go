@@ -0,0 +1,193 @@
+package inclusion
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/thebigear/models"
+)
+
+func TestCompare(t *testing.T) {
+	tests := []struct {
+		kind  string
+		value interface{}
+		op    string
+		rule  interface{}
+		want  bool
+		err   bool
+	}{
+		{kind: kindNumber, value: 2.0, op: "==", rule: 2.0, want: true},
+		{kind: kindNumber, value: 2.0, op: "!=", rule: 2.0, want: false},
+		{kind: kindNumber, value: 1.0, op: "<", rule: 2.0, want: true},
+		{kind: kindNumber, value: 2.0, op: "<", rule: 2.0, want: false},
+		{kind: kindNumber, value: 2.0, op: "<=", rule: 2.0, want: true},
+		{kind: kindNumber, value: 3.0, op: ">", rule: 2.0, want: true},
+		{kind: kindNumber, value: 2.0, op: ">", rule: 2.0, want: false},
+		{kind: kindNumber, value: 2.0, op: ">=", rule: 2.0, want: true},
+		{kind: kindNumber, value: 2.0, op: "in", rule: []interface{}{1.0, 2.0}, want: true},
+		{kind: kindNumber, value: 2.0, op: "not_in", rule: []interface{}{1.0, 2.0}, want: false},
+		{kind: kindBool, value: true, op: "==", rule: true, want: true},
+		{kind: kindBool, value: true, op: "!=", rule: true, want: false},
+		// Strings compare without case
+		{kind: kindString, value: "EN", op: "==", rule: "en", want: true},
+		{kind: kindString, value: "tr", op: "in", rule: []interface{}{"en", "TR"}, want: true},
+		{kind: kindString, value: "de", op: "not_in", rule: []interface{}{"en", "tr"}, want: true},
+		{kind: kindNumber, value: 2.0, op: "==", rule: "2", err: true},
+		{kind: kindNumber, value: 2.0, op: "~", rule: 2.0, err: true},
+		{kind: kindNumber, value: 2.0, op: "in", rule: 2.0, err: true},
+		{kind: kindBool, value: true, op: "<", rule: true, err: true},
+		{kind: kindString, value: "en", op: ">", rule: "de", err: true},
+	}
+
+	for _, test := range tests {
+		got, err := compare(test.kind, test.value, test.op, test.rule)
+		if (err != nil) != test.err {
+			t.Errorf("%v %s %v: err = %v, want an error %v", test.value, test.op, test.rule, err, test.err)
+			continue
+		}
+		if got != test.want {
+			t.Errorf("%v %s %v = %v, want %v", test.value, test.op, test.rule, got, test.want)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name string
+		rule Rule
+		err  bool
+	}{
+		{name: "valid", rule: Rule{Name: "few", Field: FieldTotalInteraction, Op: "<", Value: 2.0, Action: ActionReject}},
+		{name: "list", rule: Rule{Name: "langs", Field: FieldLang, Op: "not_in", Value: []interface{}{"en"}, Action: ActionReject}},
+		{name: "no name", rule: Rule{Field: FieldLang, Op: "==", Value: "en", Action: ActionAccept}, err: true},
+		{name: "unknown field", rule: Rule{Name: "likes", Field: "likes", Op: "<", Value: 2.0, Action: ActionReject}, err: true},
+		{name: "unknown action", rule: Rule{Name: "few", Field: FieldTotalInteraction, Op: "<", Value: 2.0, Action: "drop"}, err: true},
+		{name: "value kind", rule: Rule{Name: "few", Field: FieldTotalInteraction, Op: "<", Value: "2", Action: ActionReject}, err: true},
+		{name: "operator kind", rule: Rule{Name: "verified", Field: FieldVerified, Op: ">", Value: true, Action: ActionFlag}, err: true},
+	}
+
+	for _, test := range tests {
+		set := &RuleSet{Rules: []Rule{test.rule}}
+		if err := set.Validate(); (err != nil) != test.err {
+			t.Errorf("%s: err = %v, want an error %v", test.name, err, test.err)
+		}
+	}
+}
+
+// testExpression is an expression with given text and counts, negative
+// counts are unknown
+func testExpression(text string, favorites int, retweets int) *models.Expression {
+	expression := &models.Expression{Lang: "en", CleanText: text}
+	if favorites >= 0 && retweets >= 0 {
+		total := favorites + retweets
+		expression.TotalInteraction = &total
+		expression.Interactions = &models.Interactions{FavoriteCount: favorites, RetweetCount: retweets}
+	}
+	return expression
+}
+
+// withLang sets the language of expression
+func withLang(expression *models.Expression, lang string) *models.Expression {
+	expression.Lang = lang
+	return expression
+}
+
+func TestDecide(t *testing.T) {
+	set := &RuleSet{Rules: []Rule{
+		{Name: "trusted", Field: FieldLang, Op: "==", Value: "tr", Action: ActionAccept},
+		{Name: "popular", Field: FieldTotalInteraction, Op: ">=", Value: 100.0, Action: ActionFlag},
+		{Name: "short", Field: FieldWordCount, Op: "<", Value: 2.0, Action: ActionFlag},
+		{Name: "quiet", Field: FieldTotalInteraction, Op: "<", Value: 2.0, Action: ActionReject},
+		{Name: "not_english", Field: FieldLang, Op: "not_in", Value: []interface{}{"en", "tr"}, Action: ActionReject},
+	}}
+
+	tests := []struct {
+		name       string
+		expression *models.Expression
+		want       Decision
+	}{
+		{name: "plain", expression: testExpression("a sunny day", 5, 0), want: Decision{Accepted: true}},
+		{name: "rejected", expression: testExpression("a sunny day", 1, 0), want: Decision{Reason: "quiet"}},
+		{name: "flags add up", expression: testExpression("sunny", 100, 20), want: Decision{Accepted: true, Flags: []string{"popular", "short"}}},
+		// Flags raised before a rejection are dropped with the expression
+		{name: "flagged then rejected", expression: testExpression("sunny", 0, 0), want: Decision{Reason: "quiet", Flags: nil}},
+		// Unknown counts match neither < nor >=
+		{name: "unknown counts", expression: testExpression("a sunny day", -1, -1), want: Decision{Accepted: true}},
+		{name: "accepted right away", expression: withLang(testExpression("sunny", 0, 0), "tr"), want: Decision{Accepted: true}},
+		{name: "not in", expression: withLang(testExpression("ein sonniger Tag", 5, 0), "de"), want: Decision{Reason: "not_english"}},
+	}
+
+	for _, test := range tests {
+		if decision := set.Decide(test.expression); !reflect.DeepEqual(decision, test.want) {
+			t.Errorf("%s: decision = %+v, want %+v", test.name, decision, test.want)
+		}
+	}
+}
+
+func TestBuiltInRules(t *testing.T) {
+	tests := []struct {
+		name       string
+		expression *models.Expression
+		// want are the rejection reasons of DefaultRules, StreamRules and
+		// FreshRules, empty when accepted
+		want [3]string
+	}{
+		{name: "settled", expression: testExpression("a sunny day", 12, 3), want: [3]string{"", "", ""}},
+		{name: "empty", expression: testExpression("", 12, 3), want: [3]string{"empty_text", "empty_text", "empty_text"}},
+		{name: "quiet", expression: testExpression("a sunny day", 1, 0), want: [3]string{"low_interaction", "", ""}},
+		{name: "few favorites", expression: testExpression("a sunny day", 2, 10), want: [3]string{"few_favorites", "", ""}},
+		{name: "viral", expression: testExpression("a sunny day", 6000, 0), want: [3]string{"viral_outlier", "", ""}},
+		{name: "brand new", expression: testExpression("a sunny day", 0, 0), want: [3]string{"low_interaction", "", ""}},
+		{name: "unknown counts", expression: testExpression("a sunny day", -1, -1), want: [3]string{"", "", ""}},
+	}
+
+	sets := []*RuleSet{DefaultRules, StreamRules, FreshRules}
+	for _, test := range tests {
+		for i, set := range sets {
+			if decision := set.Decide(test.expression); decision.Reason != test.want[i] || decision.Accepted != (test.want[i] == "") {
+				t.Errorf("%s rules on %s: decision = %+v, want reason %q", set.Source, test.name, decision, test.want[i])
+			}
+		}
+	}
+
+	for _, set := range sets {
+		if err := set.Validate(); err != nil {
+			t.Errorf("%s rules: %v", set.Source, err)
+		}
+	}
+}
+
+func TestLoadRules(t *testing.T) {
+	dir, err := ioutil.TempDir("", "inclusion")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	valid := filepath.Join(dir, "rules.json")
+	ioutil.WriteFile(valid, []byte(`{"rules": [{"name": "quiet", "field": "total_interaction", "op": "<", "value": 5, "action": "reject"}]}`), 0644)
+	invalid := filepath.Join(dir, "invalid.json")
+	ioutil.WriteFile(invalid, []byte(`{"rules": [{"name": "quiet", "field": "likes", "op": "<", "value": 5, "action": "reject"}]}`), 0644)
+
+	set, err := LoadRules(valid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if set.Source != valid || len(set.Rules) != 1 {
+		t.Errorf("loaded %+v from %s", set, valid)
+	}
+	// JSON numbers compare with number facts
+	if decision := set.Decide(testExpression("a sunny day", 3, 0)); decision.Reason != "quiet" {
+		t.Errorf("decision = %+v, want quiet", decision)
+	}
+
+	if _, err := LoadRules(invalid); err == nil {
+		t.Errorf("loaded rules on an unknown field")
+	}
+	if _, err := LoadRules(filepath.Join(dir, "missing.json")); err == nil {
+		t.Errorf("loaded a missing file")
+	}
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+
+	"github.com/joho/godotenv"
+	"github.com/thebigear/database"
+	"github.com/thebigear/models"
+	"github.com/tuvistavie/structomap"
+)
+
+func init() {
+	database.Connect()
+	database.EnsureIndexes()
+	// Use snake case in all serializers
+	structomap.SetDefaultCase(structomap.SnakeCase)
+	err := godotenv.Load()
+	if err != nil {
+		log.Fatal("Error loading .env file", err)
+	}
+}
+
+func main() {
+	activate := flag.String("activate", "", "Model version to make active")
+
+	flag.Parse()
+
+	if *activate != "" {
+		query := database.Query{}
+		query["version"] = *activate
+
+		version, err := models.GetModelVersion(query)
+		if err != nil {
+			log.Fatal("Unknown model version ", *activate)
+		}
+		if err := version.Activate(); err != nil {
+			log.Fatal("Error activating ", *activate, " ", err)
+		}
+		fmt.Println("Activated", *activate)
+	}
+
+	versions, err := models.ListModelVersions(database.Query{}, nil)
+	if err != nil {
+		log.Fatal("Error listing model versions ", err)
+	}
+
+	for _, version := range *versions {
+		active := ""
+		if version.Active {
+			active = "*"
+		}
+		fmt.Printf("%1s %-24s features v%d, %d rows, MAE %.4f RMSE %.4f R2 %.4f Spearman %.4f\n",
+			active, version.Version, version.FeatureVersion, version.TrainedOn,
+			version.Metrics.MAE, version.Metrics.RMSE, version.Metrics.R2, version.Metrics.Spearman)
+	}
+}
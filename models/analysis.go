@@ -0,0 +1,25 @@
+package models
+
+import "time"
+
+// PositiveThreshold is the polarity above which an expression is positive
+const PositiveThreshold = 0.05
+
+// Analysis is the sentiment of an expression
+type Analysis struct {
+	// Polarity is the overall sentiment from -1, most negative, to 1
+	Polarity float64 `json:"polarity" bson:"polarity"`
+	// Positive, Negative and Neutral are the shares of the text carrying
+	// each sentiment, they add up to 1
+	Positive   float64   `json:"positive" bson:"positive"`
+	Negative   float64   `json:"negative" bson:"negative"`
+	Neutral    float64   `json:"neutral" bson:"neutral"`
+	Analyzer   string    `json:"analyzer" bson:"analyzer"`
+	Version    int       `json:"version" bson:"version"`
+	AnalyzedAt time.Time `json:"analyzed_at" bson:"analyzed_at"`
+}
+
+// IsPositive reports whether the polarity is above PositiveThreshold
+func (analysis *Analysis) IsPositive() bool {
+	return analysis != nil && analysis.Polarity >= PositiveThreshold
+}
@@ -0,0 +1,49 @@
+package models
+
+import (
+	"math"
+	"sort"
+)
+
+// AuthorHistory summarizes the interactions of the tweets an author posted
+// before an expression, the expression itself never counts
+type AuthorHistory struct {
+	// Size is how many earlier tweets were asked for, Count how many were
+	// found
+	Size   int     `json:"size" bson:"size"`
+	Count  int     `json:"count" bson:"count"`
+	Mean   float64 `json:"mean" bson:"mean"`
+	Median float64 `json:"median" bson:"median"`
+	Std    float64 `json:"std" bson:"std"`
+	Max    float64 `json:"max" bson:"max"`
+}
+
+// NewAuthorHistory summarizes the total interactions of earlier tweets
+func NewAuthorHistory(size int, interactions []int) *AuthorHistory {
+	history := &AuthorHistory{Size: size, Count: len(interactions)}
+	if len(interactions) == 0 {
+		return history
+	}
+
+	sorted := make([]float64, len(interactions))
+	for i, interaction := range interactions {
+		sorted[i] = float64(interaction)
+		history.Mean += sorted[i] / float64(len(interactions))
+	}
+	sort.Float64s(sorted)
+
+	middle := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		history.Median = (sorted[middle-1] + sorted[middle]) / 2
+	} else {
+		history.Median = sorted[middle]
+	}
+	history.Max = sorted[len(sorted)-1]
+
+	for _, value := range sorted {
+		history.Std += (value - history.Mean) * (value - history.Mean) / float64(len(sorted))
+	}
+	history.Std = math.Sqrt(history.Std)
+
+	return history
+}
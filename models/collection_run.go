@@ -0,0 +1,101 @@
+package models
+
+import (
+	"time"
+
+	"github.com/thebigear/database"
+	"gopkg.in/mgo.v2/bson"
+)
+
+// DBTableCollectionRuns collection name
+const DBTableCollectionRuns = "collection_runs"
+
+// InclusionStats counts what became of the tweets a run has seen
+type InclusionStats struct {
+	Seen       int `json:"seen" bson:"seen"`
+	Accepted   int `json:"accepted" bson:"accepted"`
+	Rejected   int `json:"rejected" bson:"rejected"`
+	Flagged    int `json:"flagged" bson:"flagged"`
+	Duplicates int `json:"duplicates" bson:"duplicates"`
+	// Reasons counts rejections by rule, Flags counts flags by rule
+	Reasons map[string]int `json:"reasons" bson:"reasons"`
+	Flags   map[string]int `json:"flags" bson:"flags"`
+}
+
+// NewInclusionStats creates empty InclusionStats
+func NewInclusionStats() *InclusionStats {
+	return &InclusionStats{
+		Reasons: map[string]int{},
+		Flags:   map[string]int{},
+	}
+}
+
+// Duplicate counts a tweet that was stored already
+func (stats *InclusionStats) Duplicate() {
+	stats.Seen++
+	stats.Duplicates++
+}
+
+// Reject counts a tweet rejected by the rule named reason
+func (stats *InclusionStats) Reject(reason string) {
+	stats.Seen++
+	stats.Rejected++
+	stats.Reasons[reason]++
+}
+
+// Accept counts an accepted tweet along with the rules that flagged it
+func (stats *InclusionStats) Accept(flags []string) {
+	stats.Seen++
+	stats.Accepted++
+	if len(flags) > 0 {
+		stats.Flagged++
+	}
+	for _, flag := range flags {
+		stats.Flags[flag]++
+	}
+}
+
+// CollectionRun records the inclusion decisions of a collection run, so
+// the bias of what was sampled can be understood later
+type CollectionRun struct {
+	ID             bson.ObjectId `json:"-" bson:"_id,omitempty"`
+	Campaign       string        `json:"campaign,omitempty" bson:"campaign,omitempty"`
+	Key            string        `json:"key" bson:"key"`
+	Lang           string        `json:"lang" bson:"lang"`
+	Mode           string        `json:"mode" bson:"mode"`
+	Rules          string        `json:"rules" bson:"rules"`
+	InclusionStats `bson:",inline"`
+	StartedAt      time.Time `json:"started_at" bson:"started_at"`
+	FinishedAt     time.Time `json:"finished_at" bson:"finished_at"`
+	CreatedAt      time.Time `json:"-" bson:"created_at,omitempty"`
+}
+
+// GetLastCollectionRun gets the latest scheduled run of given campaign,
+// replays are left out
+func GetLastCollectionRun(campaign string) (*CollectionRun, error) {
+	var result CollectionRun
+
+	query := database.Query{}
+	query["campaign"] = campaign
+	query["mode"] = "campaign"
+
+	err := database.Mongo.FindLast(DBTableCollectionRuns, query, &result)
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// Create a new collection run
+func (run *CollectionRun) Create() (*CollectionRun, error) {
+	run.CreatedAt = time.Now()
+	if run.FinishedAt.IsZero() {
+		run.FinishedAt = run.CreatedAt
+	}
+
+	if err := database.Mongo.Insert(DBTableCollectionRuns, run); err != nil {
+		return nil, err
+	}
+
+	return run, nil
+}
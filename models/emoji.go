@@ -0,0 +1,19 @@
+package models
+
+// Emoji is an emoji used in an expression with how many times it appears
+type Emoji struct {
+	Emoji string `json:"emoji" bson:"emoji"`
+	Count int    `json:"count" bson:"count"`
+}
+
+// Emojis array representation of Emoji
+type Emojis []Emoji
+
+// Total returns the number of emojis counted
+func (emojis Emojis) Total() int {
+	total := 0
+	for _, emoji := range emojis {
+		total += emoji.Count
+	}
+	return total
+}
@@ -14,25 +14,39 @@ const DBTableExpressions = "expressions"
 
 // Expression structure
 type Expression struct {
-	ID                 bson.ObjectId `json:"-" bson:"_id,omitempty"`
-	URLToken           string        `json:"-" bson:"token,omitempty"`
-	PostID             int64         `json:"post_id,omitempty" bson:"post_id,omitempty"`
-	FullText           string        `json:"full_text" bson:"full_text,omitempty"`
-	CleanText          string        `json:"clean_text" bson:"clean_text,omitempty"`
-	IsVerified         *bool         `json:"is_verified,omitempty" bson:"is_verified,omitempty"`
-	HasAttachment      *bool         `json:"has_attachment,omitempty" bson:"has_attachment,omitempty"`
-	Owner              string        `json:"owner,omitempty" bson:"owner,omitempty"`
-	AttachmentLabels   *string       `json:"attachment_labels,omitempty" bson:"attachment_labels,omitempty"`
-	MediaURL           string        `json:"media_url,omitempty" bson:"media_url,omitempty"`
-	Followers          *int          `json:"followers,omitempty" bson:"followers,omitempty"`
-	Following          *int          `json:"following,omitempty" bson:"following,omitempty"`
-	PostCount          *int          `json:"post_count,omitempty" bson:"post_count,omitempty"`
-	LastTenInteraction *int          `json:"last_ten_interaction,omitempty" bson:"last_ten_interaction,omitempty"`
-	TotalInteraction   *int          `json:"total_interaction,omitempty" bson:"total_interaction,omitempty"`
-	//Analysis  Analysis      `json:"analysis,omitempty" bson:"analysis,omitempty"`
-	CreatedAt time.Time `json:"-" bson:"created_at,omitempty"`
-	UpdatedAt time.Time `json:"-" bson:"updated_at,omitempty"`
-	DeletedAt time.Time `json:"-" bson:"deleted_at,omitempty"`
+	ID                 bson.ObjectId  `json:"-" bson:"_id,omitempty"`
+	URLToken           string         `json:"-" bson:"token,omitempty"`
+	PostID             int64          `json:"post_id,omitempty" bson:"post_id,omitempty"`
+	FullText           string         `json:"full_text" bson:"full_text,omitempty"`
+	CleanText          string         `json:"clean_text" bson:"clean_text,omitempty"`
+	Emojis             Emojis         `json:"emojis,omitempty" bson:"emojis,omitempty"`
+	Lang               string         `json:"lang,omitempty" bson:"lang,omitempty"`
+	IsVerified         *bool          `json:"is_verified,omitempty" bson:"is_verified,omitempty"`
+	HasAttachment      *bool          `json:"has_attachment,omitempty" bson:"has_attachment,omitempty"`
+	Owner              string         `json:"owner,omitempty" bson:"owner,omitempty"`
+	OwnerCreatedAt     time.Time      `json:"owner_created_at,omitempty" bson:"owner_created_at,omitempty"`
+	PostedAt           time.Time      `json:"posted_at,omitempty" bson:"posted_at,omitempty"`
+	AttachmentLabels   *string        `json:"attachment_labels,omitempty" bson:"attachment_labels,omitempty"`
+	Labels             Labels         `json:"labels,omitempty" bson:"labels,omitempty"`
+	MediaURL           string         `json:"media_url,omitempty" bson:"media_url,omitempty"`
+	Media              []Media        `json:"media,omitempty" bson:"media,omitempty"`
+	Followers          *int           `json:"followers,omitempty" bson:"followers,omitempty"`
+	Following          *int           `json:"following,omitempty" bson:"following,omitempty"`
+	PostCount          *int           `json:"post_count,omitempty" bson:"post_count,omitempty"`
+	LastTenInteraction *int           `json:"last_ten_interaction,omitempty" bson:"last_ten_interaction,omitempty"`
+	AuthorHistory      *AuthorHistory `json:"author_history,omitempty" bson:"author_history,omitempty"`
+	TotalInteraction   *int           `json:"total_interaction,omitempty" bson:"total_interaction,omitempty"`
+	Interactions       *Interactions  `json:"interactions,omitempty" bson:"interactions,omitempty"`
+	Mature             *bool          `json:"mature,omitempty" bson:"mature,omitempty"`
+	Flags              []string       `json:"flags,omitempty" bson:"flags,omitempty"`
+	Campaign           string         `json:"campaign,omitempty" bson:"campaign,omitempty"`
+	SnapshotStage      *int           `json:"snapshot_stage,omitempty" bson:"snapshot_stage,omitempty"`
+	Features           *Features      `json:"features,omitempty" bson:"features,omitempty"`
+	Analysis           *Analysis      `json:"analysis,omitempty" bson:"analysis,omitempty"`
+	Prediction         *Prediction    `json:"prediction,omitempty" bson:"prediction,omitempty"`
+	CreatedAt          time.Time      `json:"-" bson:"created_at,omitempty"`
+	UpdatedAt          time.Time      `json:"-" bson:"updated_at,omitempty"`
+	DeletedAt          time.Time      `json:"-" bson:"deleted_at,omitempty"`
 }
 
 // Expressions array representation of Expression
@@ -116,6 +130,14 @@ func (expression *Expression) Delete() error {
 	return err
 }
 
+// SetLabels sets labels along with the legacy attachment labels derived from them
+func (expression *Expression) SetLabels(labels Labels) {
+	legacy := labels.String()
+
+	expression.Labels = labels
+	expression.AttachmentLabels = &legacy
+}
+
 // ExpressionSerializer used in constructing maps to output JSON
 type ExpressionSerializer struct {
 	*structomap.Base
@@ -124,12 +146,21 @@ type ExpressionSerializer struct {
 // NewExpressionSerializer creates a new ExpressionSerializer
 func NewExpressionSerializer() *ExpressionSerializer {
 	s := &ExpressionSerializer{structomap.New()}
-	s.Pick("RawText", "CleanText", "Source", "Image", "Owner", "Positive", "Polarity").
+	s.Pick("RawText", "CleanText", "Source", "Image", "Owner", "Lang", "Campaign", "Media", "Labels", "Features", "Analysis", "Prediction").
 		PickFunc(func(t interface{}) interface{} {
 			return t.(time.Time).Format(time.RFC3339)
 		}, "CreatedAt", "UpdatedAt").
 		AddFunc("ID", func(expression interface{}) interface{} {
 			return expression.(Expression).URLToken
+		}).
+		AddFunc("Positive", func(expression interface{}) interface{} {
+			return expression.(Expression).Analysis.IsPositive()
+		}).
+		AddFunc("Polarity", func(expression interface{}) interface{} {
+			if analysis := expression.(Expression).Analysis; analysis != nil {
+				return analysis.Polarity
+			}
+			return nil
 		})
 
 	return s
@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+// Features is the feature map of an expression along with the version of
+// the extractor that computed it
+type Features struct {
+	Version     int                `json:"version" bson:"version"`
+	Values      map[string]float64 `json:"values" bson:"values"`
+	ExtractedAt time.Time          `json:"extracted_at" bson:"extracted_at"`
+}
+
+// Value returns the named feature and whether it is known
+func (features *Features) Value(name string) (float64, bool) {
+	if features == nil {
+		return 0, false
+	}
+	value, ok := features.Values[name]
+	return value, ok
+}
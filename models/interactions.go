@@ -0,0 +1,31 @@
+package models
+
+import "time"
+
+// Interactions are the interaction counters of a tweet as observed at a
+// point in time. Quote and reply counts are only returned by some API
+// tiers and are zero otherwise.
+type Interactions struct {
+	FavoriteCount int       `json:"favorite_count" bson:"favorite_count"`
+	RetweetCount  int       `json:"retweet_count" bson:"retweet_count"`
+	QuoteCount    int       `json:"quote_count" bson:"quote_count"`
+	ReplyCount    int       `json:"reply_count" bson:"reply_count"`
+	ObservedAt    time.Time `json:"observed_at" bson:"observed_at"`
+	// ObservationAge is the number of seconds from posting to ObservedAt,
+	// nil when the posting time is unknown
+	ObservationAge *int64 `json:"observation_age,omitempty" bson:"observation_age,omitempty"`
+}
+
+// Total is the sum of favorites and retweets, the total interaction
+func (interactions *Interactions) Total() int {
+	return interactions.FavoriteCount + interactions.RetweetCount
+}
+
+// Age is the time from posting to the observation, false when the posting
+// time is unknown
+func (interactions *Interactions) Age() (time.Duration, bool) {
+	if interactions.ObservationAge == nil {
+		return 0, false
+	}
+	return time.Duration(*interactions.ObservationAge) * time.Second, true
+}
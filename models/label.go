@@ -0,0 +1,39 @@
+package models
+
+import "strings"
+
+// Label is a label detected on an attached image
+type Label struct {
+	Name       string          `json:"name" bson:"name"`
+	Confidence float64         `json:"confidence" bson:"confidence"`
+	Parents    []string        `json:"parents,omitempty" bson:"parents,omitempty"`
+	Instances  []LabelInstance `json:"instances,omitempty" bson:"instances,omitempty"`
+}
+
+// LabelInstance is an occurrence of a label with its bounding box, given
+// as ratios of the image size
+type LabelInstance struct {
+	Confidence float64 `json:"confidence" bson:"confidence"`
+	Left       float64 `json:"left" bson:"left"`
+	Top        float64 `json:"top" bson:"top"`
+	Width      float64 `json:"width" bson:"width"`
+	Height     float64 `json:"height" bson:"height"`
+}
+
+// Labels array representation of Label
+type Labels []Label
+
+// Names returns the names of labels
+func (labels Labels) Names() []string {
+	names := make([]string, len(labels))
+	for index, label := range labels {
+		names[index] = label.Name
+	}
+	return names
+}
+
+// String returns label names joined with spaces, the legacy
+// attachment_labels representation
+func (labels Labels) String() string {
+	return strings.Join(labels.Names(), " ")
+}
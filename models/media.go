@@ -0,0 +1,55 @@
+package models
+
+// Media types as reported by Twitter
+const (
+	MediaTypePhoto       = "photo"
+	MediaTypeVideo       = "video"
+	MediaTypeAnimatedGIF = "animated_gif"
+)
+
+// Media is a photo, video or GIF attached to an expression. Videos and GIFs
+// are labeled by their thumbnail. View counts are not kept, the standard
+// v1.1 search and lookup endpoints do not return them.
+type Media struct {
+	ID   int64  `json:"id,omitempty" bson:"id,omitempty"`
+	Type string `json:"type" bson:"type"`
+	// URL is the photo itself or the thumbnail of a video or GIF
+	URL            string `json:"url" bson:"url"`
+	VideoURL       string `json:"video_url,omitempty" bson:"video_url,omitempty"`
+	Width          int    `json:"width,omitempty" bson:"width,omitempty"`
+	Height         int    `json:"height,omitempty" bson:"height,omitempty"`
+	DurationMillis int    `json:"duration_millis,omitempty" bson:"duration_millis,omitempty"`
+	Hash           string `json:"hash,omitempty" bson:"hash,omitempty"`
+	Labels         Labels `json:"labels,omitempty" bson:"labels,omitempty"`
+	Error          string `json:"error,omitempty" bson:"error,omitempty"`
+}
+
+// SetMedia sets the attachments of expression along with the media url of
+// the first one and the labels of all of them
+func (expression *Expression) SetMedia(media []Media) {
+	expression.Media = media
+	if len(media) == 0 {
+		return
+	}
+
+	expression.MediaURL = media[0].URL
+
+	// Keep the most confident occurrence of each label
+	var labels Labels
+	indexes := map[string]int{}
+	for _, item := range media {
+		for _, label := range item.Labels {
+			index, ok := indexes[label.Name]
+			if !ok {
+				indexes[label.Name] = len(labels)
+				labels = append(labels, label)
+			} else if label.Confidence > labels[index].Confidence {
+				labels[index] = label
+			}
+		}
+	}
+
+	if len(labels) > 0 {
+		expression.SetLabels(labels)
+	}
+}
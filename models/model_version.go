@@ -0,0 +1,140 @@
+package models
+
+import (
+	"time"
+
+	"github.com/thebigear/database"
+	"gopkg.in/mgo.v2/bson"
+)
+
+// DBTableModelVersions collection name
+const DBTableModelVersions = "model_versions"
+
+// Metrics measure how close predictions are to observed values
+type Metrics struct {
+	Count    int     `json:"count" bson:"count"`
+	MAE      float64 `json:"mae" bson:"mae"`
+	RMSE     float64 `json:"rmse" bson:"rmse"`
+	R2       float64 `json:"r2" bson:"r2"`
+	Spearman float64 `json:"spearman" bson:"spearman"`
+}
+
+// ModelVersion is a trained engagement model recorded in the registry. At
+// most one version is active, it is the one predictions are made with.
+type ModelVersion struct {
+	ID      bson.ObjectId `json:"-" bson:"_id,omitempty"`
+	Version string        `json:"version" bson:"version"`
+	Kind    string        `json:"kind" bson:"kind"`
+	// DatasetQuery is the query and split ratios the model was trained on
+	DatasetQuery   string  `json:"dataset_query" bson:"dataset_query"`
+	FeatureVersion int     `json:"feature_version" bson:"feature_version"`
+	Metrics        Metrics `json:"metrics" bson:"metrics"`
+	TrainedOn      int     `json:"trained_on" bson:"trained_on"`
+	ArtifactPath   string  `json:"artifact_path,omitempty" bson:"artifact_path,omitempty"`
+	// Artifact is the saved model itself, so versions can be loaded on
+	// machines without the artifact file
+	Artifact  []byte    `json:"-" bson:"artifact,omitempty"`
+	Active    bool      `json:"active" bson:"active"`
+	CreatedAt time.Time `json:"created_at" bson:"created_at,omitempty"`
+	UpdatedAt time.Time `json:"-" bson:"updated_at,omitempty"`
+}
+
+// ModelVersions array representation of ModelVersion
+type ModelVersions []ModelVersion
+
+// ListModelVersions lists model versions, newest first
+func ListModelVersions(query database.Query, paginationParams *database.PaginationParams) (*ModelVersions, error) {
+	var result ModelVersions
+
+	if paginationParams == nil {
+		paginationParams = database.NewPaginationParams()
+		paginationParams.SortBy = "-created_at"
+	}
+
+	err := database.Mongo.FindAll(DBTableModelVersions, query, &result, paginationParams)
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// GetModelVersion gets a model version matching query
+func GetModelVersion(query database.Query) (*ModelVersion, error) {
+	var result ModelVersion
+
+	err := database.Mongo.FindOne(DBTableModelVersions, query, &result)
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// GetActiveModelVersion gets the active model version. While a version is
+// being activated the last one activated wins.
+func GetActiveModelVersion() (*ModelVersion, error) {
+	var result ModelVersion
+
+	query := database.Query{}
+	query["active"] = true
+
+	err := database.Mongo.FindOneSelect(DBTableModelVersions, query, nil, "-updated_at", &result)
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// GetActiveVersion gets the version of the active model version without
+// loading its artifact
+func GetActiveVersion() (string, error) {
+	var result ModelVersion
+
+	query := database.Query{}
+	query["active"] = true
+
+	err := database.Mongo.FindOneSelect(DBTableModelVersions, query, database.Query{"version": 1}, "-updated_at", &result)
+	if err != nil {
+		return "", err
+	}
+	return result.Version, nil
+}
+
+// Create records a new model version
+func (version *ModelVersion) Create() (*ModelVersion, error) {
+	version.CreatedAt = time.Now()
+	version.UpdatedAt = version.CreatedAt
+
+	if err := database.Mongo.Insert(DBTableModelVersions, version); err != nil {
+		return nil, err
+	}
+
+	return version, nil
+}
+
+// Activate makes this the only active model version
+func (version *ModelVersion) Activate() error {
+	query := database.Query{}
+	query["version"] = version.Version
+
+	version.Active = true
+	version.UpdatedAt = time.Now()
+
+	change := database.DocumentChange{
+		Update:    database.Query{"$set": database.Query{"active": true, "updated_at": version.UpdatedAt}},
+		ReturnNew: true,
+	}
+
+	// Activate the target first, so there is an active version throughout
+	if err := database.Mongo.Update(DBTableModelVersions, query, change, nil); err != nil {
+		return err
+	}
+
+	others := database.Query{}
+	others["version"] = database.Query{"$ne": version.Version}
+	others["active"] = true
+
+	_, err := database.Mongo.UpdateAll(DBTableModelVersions, others, database.Query{
+		"$set": database.Query{"active": false, "updated_at": time.Now()},
+	})
+	return err
+}
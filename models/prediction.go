@@ -0,0 +1,34 @@
+package models
+
+import "time"
+
+// Draft is a tweet that has not been posted yet, described by its text and
+// the stats of its author
+type Draft struct {
+	Text               string `json:"text"`
+	Lang               string `json:"lang,omitempty"`
+	Followers          int    `json:"followers"`
+	Following          int    `json:"following"`
+	PostCount          int    `json:"post_count"`
+	IsVerified         bool   `json:"is_verified"`
+	LastTenInteraction *int   `json:"last_ten_interaction,omitempty"`
+	// AuthorHistory summarizes the author's earlier tweets
+	AuthorHistory    *AuthorHistory `json:"author_history,omitempty"`
+	AccountCreatedAt time.Time      `json:"account_created_at,omitempty"`
+	// PostAt is when the draft would be posted, now when it is not set
+	PostAt     time.Time `json:"post_at,omitempty"`
+	MediaCount int       `json:"media_count,omitempty"`
+	// Labels of the attached images, a label implies at least one image
+	Labels []string `json:"labels,omitempty"`
+}
+
+// Prediction is the total interaction a model expects for an expression
+// along with a confidence interval
+type Prediction struct {
+	Interaction float64   `json:"interaction" bson:"interaction"`
+	Lower       float64   `json:"lower" bson:"lower"`
+	Upper       float64   `json:"upper" bson:"upper"`
+	Confidence  float64   `json:"confidence" bson:"confidence"`
+	Model       string    `json:"model" bson:"model"`
+	PredictedAt time.Time `json:"predicted_at" bson:"predicted_at"`
+}
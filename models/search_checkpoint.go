@@ -0,0 +1,65 @@
+package models
+
+import (
+	"time"
+
+	"github.com/thebigear/database"
+	"gopkg.in/mgo.v2/bson"
+)
+
+// DBTableSearchCheckpoints collection name
+const DBTableSearchCheckpoints = "search_checkpoints"
+
+// SearchCheckpoint keeps the paging position of a search key in a language
+// between runs
+type SearchCheckpoint struct {
+	ID   bson.ObjectId `json:"-" bson:"_id,omitempty"`
+	Key  string        `json:"key" bson:"key"`
+	Lang string        `json:"lang" bson:"lang"`
+	// SinceID is the highest tweet id of the last fully collected window
+	SinceID int64 `json:"since_id" bson:"since_id"`
+	// MaxID is where an unfinished window resumes, 0 when there is none
+	MaxID int64 `json:"max_id" bson:"max_id"`
+	// NewestID is the highest tweet id seen in the unfinished window
+	NewestID  int64     `json:"newest_id" bson:"newest_id"`
+	CreatedAt time.Time `json:"-" bson:"created_at,omitempty"`
+	UpdatedAt time.Time `json:"-" bson:"updated_at,omitempty"`
+}
+
+// GetSearchCheckpoint gets the checkpoint of given search key and language
+func GetSearchCheckpoint(key string, lang string) (*SearchCheckpoint, error) {
+	var result SearchCheckpoint
+
+	query := database.Query{}
+	query["key"] = key
+	query["lang"] = lang
+
+	err := database.Mongo.FindOne(DBTableSearchCheckpoints, query, &result)
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// Save creates or updates the checkpoint of its search key and language
+func (checkpoint *SearchCheckpoint) Save() (*SearchCheckpoint, error) {
+	query := database.Query{}
+	query["key"] = checkpoint.Key
+	query["lang"] = checkpoint.Lang
+
+	checkpoint.UpdatedAt = time.Now()
+	if checkpoint.CreatedAt.IsZero() {
+		checkpoint.CreatedAt = checkpoint.UpdatedAt
+	}
+
+	change := database.DocumentChange{
+		Update:    checkpoint,
+		Upsert:    true,
+		ReturnNew: true,
+	}
+
+	result := &SearchCheckpoint{}
+	err := database.Mongo.Update(DBTableSearchCheckpoints, query, change, result)
+
+	return result, err
+}
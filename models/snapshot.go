@@ -0,0 +1,77 @@
+package models
+
+import (
+	"time"
+
+	"github.com/thebigear/database"
+	"gopkg.in/mgo.v2/bson"
+)
+
+// DBTableSnapshots collection name
+const DBTableSnapshots = "snapshots"
+
+// Snapshot is a timestamped interaction count of an expression
+type Snapshot struct {
+	ID               bson.ObjectId `json:"-" bson:"_id,omitempty"`
+	ExpressionToken  string        `json:"expression_id" bson:"expression_token,omitempty"`
+	PostID           int64         `json:"post_id,omitempty" bson:"post_id,omitempty"`
+	Stage            int           `json:"stage" bson:"stage"`
+	FavoriteCount    int           `json:"favorite_count" bson:"favorite_count"`
+	RetweetCount     int           `json:"retweet_count" bson:"retweet_count"`
+	QuoteCount       int           `json:"quote_count" bson:"quote_count"`
+	ReplyCount       int           `json:"reply_count" bson:"reply_count"`
+	TotalInteraction int           `json:"total_interaction" bson:"total_interaction"`
+	SampledAt        time.Time     `json:"sampled_at" bson:"sampled_at,omitempty"`
+	// ObservationAge is the number of seconds from posting to SampledAt,
+	// nil when the posting time is unknown
+	ObservationAge *int64    `json:"observation_age,omitempty" bson:"observation_age,omitempty"`
+	CreatedAt      time.Time `json:"-" bson:"created_at,omitempty"`
+}
+
+// Snapshots array representation of Snapshot
+type Snapshots []Snapshot
+
+// ListSnapshots lists snapshots matching with query
+func ListSnapshots(query database.Query, paginationParams *database.PaginationParams) (*Snapshots, error) {
+	var result Snapshots
+
+	if paginationParams == nil {
+		paginationParams = database.NewPaginationParams()
+		paginationParams.SortBy = "sampled_at"
+	}
+
+	err := database.Mongo.FindAll(DBTableSnapshots, query, &result, paginationParams)
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// GetLatestSnapshot gets the last snapshot taken of postID
+func GetLatestSnapshot(postID int64) (*Snapshot, error) {
+	var result Snapshot
+
+	query := database.Query{}
+	query["post_id"] = postID
+
+	err := database.Mongo.FindLast(DBTableSnapshots, query, &result)
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// Create a new snapshot
+func (snapshot *Snapshot) Create() (*Snapshot, error) {
+
+	snapshot.CreatedAt = time.Now()
+	if snapshot.SampledAt.IsZero() {
+		snapshot.SampledAt = snapshot.CreatedAt
+	}
+
+	if err := database.Mongo.Insert(DBTableSnapshots, snapshot); err != nil {
+		return nil, err
+	}
+
+	return snapshot, nil
+}
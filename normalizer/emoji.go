@@ -0,0 +1,127 @@
+package normalizer
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/thebigear/models"
+)
+
+const (
+	zeroWidthJoiner   = '\u200d'
+	variationSelector = '\ufe0f'
+	keycap            = '\u20e3'
+)
+
+// ExtractEmojis removes emojis from text and returns them counted, most
+// frequent first. Flags, skin tones and joined sequences count as one.
+func ExtractEmojis(text string) (string, models.Emojis) {
+	runes := []rune(text)
+	counts := map[string]int{}
+	var order []string
+
+	var clean strings.Builder
+	for i := 0; i < len(runes); {
+		end := emojiEnd(runes, i)
+		if end == i {
+			clean.WriteRune(runes[i])
+			i++
+			continue
+		}
+
+		emoji := string(runes[i:end])
+		if counts[emoji] == 0 {
+			order = append(order, emoji)
+		}
+		counts[emoji]++
+
+		// Keep words around the emoji apart
+		clean.WriteRune(' ')
+		i = end
+	}
+
+	emojis := make(models.Emojis, len(order))
+	for index, emoji := range order {
+		emojis[index] = models.Emoji{
+			Emoji: emoji,
+			Count: counts[emoji],
+		}
+	}
+	sort.SliceStable(emojis, func(i, j int) bool {
+		return emojis[i].Count > emojis[j].Count
+	})
+
+	return clean.String(), emojis
+}
+
+// emojiEnd returns where the emoji sequence starting at i ends, or i when
+// there is no emoji there
+func emojiEnd(runes []rune, i int) int {
+	// A flag is a pair of regional indicators
+	if isRegionalIndicator(runes[i]) {
+		if i+1 < len(runes) && isRegionalIndicator(runes[i+1]) {
+			return i + 2
+		}
+		return i + 1
+	}
+
+	// Keycaps are a digit, # or * followed by an optional variation
+	// selector and the combining keycap
+	if strings.ContainsRune("0123456789#*", runes[i]) {
+		j := i + 1
+		if j < len(runes) && runes[j] == variationSelector {
+			j++
+		}
+		if j < len(runes) && runes[j] == keycap {
+			return j + 1
+		}
+		return i
+	}
+
+	if !isEmoji(runes[i]) {
+		return i
+	}
+
+	end := i + 1
+	for end < len(runes) {
+		switch {
+		case runes[end] == variationSelector || isSkinTone(runes[end]):
+			end++
+		case runes[end] == zeroWidthJoiner && end+1 < len(runes) && isEmoji(runes[end+1]):
+			end += 2
+		default:
+			return end
+		}
+	}
+
+	return end
+}
+
+func isRegionalIndicator(r rune) bool {
+	return r >= 0x1f1e6 && r <= 0x1f1ff
+}
+
+func isSkinTone(r rune) bool {
+	return r >= 0x1f3fb && r <= 0x1f3ff
+}
+
+// isEmoji reports whether r is in one of the emoji blocks
+func isEmoji(r rune) bool {
+	switch {
+	case r >= 0x1f300 && r <= 0x1f5ff: // Miscellaneous Symbols and Pictographs
+		return true
+	case r >= 0x1f600 && r <= 0x1f64f: // Emoticons
+		return true
+	case r >= 0x1f680 && r <= 0x1f6ff: // Transport and Map Symbols
+		return true
+	case r >= 0x1f900 && r <= 0x1f9ff: // Supplemental Symbols and Pictographs
+		return true
+	case r >= 0x1fa70 && r <= 0x1faff: // Symbols and Pictographs Extended-A
+		return true
+	case r >= 0x2600 && r <= 0x27bf: // Miscellaneous Symbols and Dingbats
+		return true
+	case r == 0x2b50 || r == 0x2b55 || r == 0x2764 || r == 0x203c || r == 0x2049:
+		return true
+	}
+	return false
+}
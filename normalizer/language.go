@@ -0,0 +1,86 @@
+package normalizer
+
+import (
+	"html"
+	"regexp"
+	"strings"
+
+	"golang.org/x/text/cases"
+	"golang.org/x/text/language"
+	"golang.org/x/text/unicode/norm"
+)
+
+// elisionReg matches elided articles and pronouns like l' or qu' glued to
+// the next word
+var elisionReg = regexp.MustCompile(`(^|\s)(l|d|j|m|n|s|t|c|qu|jusqu|lorsqu|puisqu|dell|all|dall|nell|sull|un)['\x{2019}](\p{L})`)
+
+// elidingLanguages are the languages elisions are split in
+var elidingLanguages = map[string]bool{
+	"fr": true,
+	"it": true,
+	"ca": true,
+}
+
+// baseLanguage returns the primary subtag of a tweet language like "en-gb"
+func baseLanguage(lang string) string {
+	lang = strings.ToLower(lang)
+	if index := strings.IndexAny(lang, "-_"); index >= 0 {
+		lang = lang[:index]
+	}
+	return lang
+}
+
+// caser returns the case folding of lang. Turkish and Azerbaijani map
+// dotted and dotless i their own way, every other language is folded.
+func caser(lang string) cases.Caser {
+	switch baseLanguage(lang) {
+	case "tr":
+		return cases.Lower(language.Turkish)
+	case "az":
+		return cases.Lower(language.Azerbaijani)
+	}
+	return cases.Fold()
+}
+
+// NormalizeLang normalizes text like Normalize, with the case folding and
+// cleaning rules of lang. Unknown and empty languages get the defaults.
+func NormalizeLang(text string, lang string) string {
+	text = html.UnescapeString(text)
+	text = norm.NFC.String(text)
+	text, _ = ExtractEmojis(text)
+	text = caser(lang).String(text)
+
+	if elidingLanguages[baseLanguage(lang)] {
+		text = elisionReg.ReplaceAllString(text, "$1$2 $3")
+	}
+
+	text = symbolsReg.ReplaceAllString(text, " ")
+	// Run twice, neighbouring matches share the whitespace between them
+	text = danglingReg.ReplaceAllString(text, " ")
+	text = danglingReg.ReplaceAllString(text, " ")
+
+	return strings.TrimSpace(whitespaceReg.ReplaceAllString(text, " "))
+}
+
+// FoldLang composes text to NFC and case folds it the way lang does,
+// leaving symbols alone
+func FoldLang(text string, lang string) string {
+	return caser(lang).String(norm.NFC.String(text))
+}
+
+// RemoveStopwords drops the stopwords of lang from normalized text. Text of
+// languages without a stopword list is returned as is.
+func RemoveStopwords(text string, lang string) string {
+	stopwords, ok := stopwords[baseLanguage(lang)]
+	if !ok {
+		return text
+	}
+
+	var words []string
+	for _, word := range strings.Fields(text) {
+		if !stopwords[word] {
+			words = append(words, word)
+		}
+	}
+	return strings.Join(words, " ")
+}
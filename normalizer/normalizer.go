@@ -0,0 +1,39 @@
+// Package normalizer normalizes tweet text without losing non-Latin
+// scripts, and pulls emojis out of it.
+package normalizer
+
+import (
+	"html"
+	"regexp"
+
+	"github.com/thebigear/models"
+	"golang.org/x/text/unicode/norm"
+)
+
+var (
+	// symbolsReg matches anything but letters, marks, numbers, whitespace
+	// and the apostrophes and hyphens words are made of
+	symbolsReg = regexp.MustCompile(`[^\p{L}\p{M}\p{N}\s'\x{2019}-]+`)
+	// danglingReg matches apostrophes and hyphens not inside a word
+	danglingReg = regexp.MustCompile(`(^|\s)['\x{2019}-]+|['\x{2019}-]+(\s|$)`)
+	// whitespaceReg matches runs of whitespace
+	whitespaceReg = regexp.MustCompile(`\s+`)
+)
+
+// Normalize decodes HTML entities, composes text to NFC, drops emojis,
+// case folds it and replaces symbols with spaces. Letters of every script
+// are kept.
+func Normalize(text string) string {
+	return NormalizeLang(text, "")
+}
+
+// Fold composes text to NFC and case folds it, leaving symbols alone
+func Fold(text string) string {
+	return FoldLang(text, "")
+}
+
+// Emojis decodes HTML entities of text and counts the emojis in it
+func Emojis(text string) models.Emojis {
+	_, emojis := ExtractEmojis(norm.NFC.String(html.UnescapeString(text)))
+	return emojis
+}
@@ -0,0 +1,50 @@
+package normalizer
+
+import "strings"
+
+// stopwordLists are the stopwords of each language, already case folded.
+// Negations are left out on purpose, they carry sentiment.
+var stopwordLists = map[string]string{
+	"en": `a an the and or but if then so than that this these those there here
+		of to in on at by for with about from into over under up down out off
+		is am are was were be been being have has had do does did i me my we our
+		you your he him his she her it its they them their what which who whom
+		as just very too can will would should could rt amp`,
+	"es": `el la los las un una unos unas y o pero si de del a al en con por para
+		que se lo le les su sus es son fue era ser estar está están he ha han yo
+		tu él ella nosotros ellos mi mis me te nos como más muy ya este esta esto`,
+	"fr": `le la les l un une des et ou mais si de du d à au aux en dans par pour
+		sur avec que qu qui se s ce c cette ces est sont été être avoir a ai as
+		je j tu il elle nous vous ils elles mon ma mes ton ta tes son sa ses me m
+		te t lui leur y très plus`,
+	"de": `der die das den dem des ein eine einer eines einem einen und oder aber
+		wenn dann so als dass zu in im an am auf aus bei mit nach von vor für über
+		ist sind war waren sein bin bist hat haben habe ich du er sie es wir ihr
+		mein dein sich auch noch sehr schon`,
+	"it": `il lo la i gli le un uno una l e o ma se di del della dei delle a al
+		alla in nel nella con per su da dal che chi si è sono era essere ho ha hanno
+		io tu lui lei noi voi loro mio mia tuo tua suo sua mi ti ci vi anche molto
+		dell all dall nell sull`,
+	"pt": `o a os as um uma uns umas e ou mas se de do da dos das em no na nos nas
+		com por para que é são foi era ser estar está eu tu ele ela nós vós eles
+		elas meu minha seu sua me te se lhe muito mais já também`,
+	"tr": `ve ile ama fakat veya ya da de ki bu şu o bir için gibi kadar daha çok en
+		ben sen biz siz onlar bana sana ona mı mi mu mü ne neden nasıl her şey
+		olan olarak diye ise`,
+	"nl": `de het een en of maar als dan dat dit die deze van in op te aan met voor
+		door over bij uit naar is zijn was waren ben heb heeft hebben ik jij je
+		hij zij wij we jullie mijn jouw zijn haar ons ook nog zeer al`,
+}
+
+// stopwords holds stopwordLists as sets
+var stopwords = map[string]map[string]bool{}
+
+func init() {
+	for lang, list := range stopwordLists {
+		set := map[string]bool{}
+		for _, word := range strings.Fields(list) {
+			set[word] = true
+		}
+		stopwords[lang] = set
+	}
+}
@@ -1,13 +1,16 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"log"
-	"regexp"
 
 	"github.com/joho/godotenv"
 	"github.com/thebigear/database"
+	"github.com/thebigear/inclusion"
 	"github.com/thebigear/models"
+	"github.com/thebigear/twitterear"
+	"github.com/thebigear/utils"
 	"github.com/tuvistavie/structomap"
 )
 
@@ -23,6 +26,23 @@ func init() {
 }
 
 func main() {
+	rulesPath := flag.String("rules", utils.GetEnvOrDefault("INCLUSION_RULES", ""), "JSON inclusion rules, expressions they reject are deleted (default the built in rules)")
+	cleanPolicy := flag.String("clean", utils.GetEnvOrDefault("CLEAN_POLICY", ""), "Entity policies clean text is rebuilt with, like hashtags=keep,urls=replace")
+
+	flag.Parse()
+
+	policy, err := twitterear.ParseCleanPolicy(*cleanPolicy)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	rules := inclusion.DefaultRules
+	if *rulesPath != "" {
+		rules, err = inclusion.LoadRules(*rulesPath)
+		if err != nil {
+			log.Fatal("Error loading inclusion rules ", err)
+		}
+	}
 
 	query := database.Query{}
 	query["deleted_at"] = nil
@@ -33,25 +53,31 @@ func main() {
 
 	expressions, _ = models.ListExpressions(query, paginationParams)
 
-	reg, err := regexp.Compile("[^a-zA-Z0-9 ]+")
-	if err != nil {
-		log.Fatal(err)
-	}
-
 	for _, tweet := range *expressions {
 
-		tot_interaction := tweet.TotalInteraction
+		// Interaction counts of immature expressions are still growing,
+		// they are only judged like fresh tweets until a snapshot matures them
+		immature := tweet.Mature != nil && !*tweet.Mature
+		judge := rules
+		if immature {
+			judge = inclusion.FreshRules
+		}
+		decision := judge.Decide(&tweet)
 
-		if *tot_interaction > 5000 {
+		if !decision.Accepted {
 			tweet.Delete()
-			fmt.Println("DELETED")
+			fmt.Println("DELETED: ", decision.Reason)
 		} else {
 
+			// Rebuild from the full text, normalizing clean text again
+			// would mangle its placeholder tokens
 			fmt.Println("OLD: ", tweet.CleanText)
-			processedString := reg.ReplaceAllString(tweet.CleanText, "")
-			tweet.CleanText = processedString
+			tweet.CleanText, tweet.Emojis = twitterear.CleanTextWithPolicy(tweet.FullText, tweet.Lang, policy)
+			if !immature {
+				tweet.Flags = decision.Flags
+			}
 
-			fmt.Println("NEW: ", processedString)
+			fmt.Println("NEW: ", tweet.CleanText)
 			tweet.Update()
 
 		}
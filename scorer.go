@@ -0,0 +1,134 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+
+	"github.com/joho/godotenv"
+	"github.com/thebigear/database"
+	"github.com/thebigear/dataset"
+	"github.com/thebigear/engagement"
+	"github.com/thebigear/models"
+	"github.com/tuvistavie/structomap"
+)
+
+func init() {
+	database.Connect()
+	database.EnsureIndexes()
+	// Use snake case in all serializers
+	structomap.SetDefaultCase(structomap.SnakeCase)
+	err := godotenv.Load()
+	if err != nil {
+		log.Fatal("Error loading .env file", err)
+	}
+}
+
+func main() {
+	versionFlag := flag.String("version", "", "Model version to score with (default the active version)")
+	lang := flag.String("lang", "", "Only score expressions in this language")
+	rescore := flag.Bool("rescore", false, "Score expressions already scored by this version again")
+	reportOnly := flag.Bool("report", false, "Only report on stored predictions, without scoring")
+	confidence := flag.Float64("confidence", 0.9, "Confidence of predicted intervals")
+	batch := flag.Int("batch", 500, "Expressions loaded at a time")
+
+	flag.Parse()
+
+	var version *models.ModelVersion
+	var err error
+	if *versionFlag != "" {
+		version, err = models.GetModelVersion(database.Query{"version": *versionFlag})
+	} else {
+		version, err = models.GetActiveModelVersion()
+	}
+	if err != nil {
+		log.Fatal("Error finding model version ", err)
+	}
+
+	model, err := engagement.LoadVersion(version)
+	if err != nil {
+		log.Fatal("Error loading model version ", version.Version, " ", err)
+	}
+	fmt.Println("version:", model.Version)
+
+	query := database.Query{}
+	query["deleted_at"] = nil
+	if *lang != "" {
+		query["lang"] = *lang
+	}
+
+	if !*reportOnly {
+		scored := 0
+		err = dataset.Each(query, *batch, func(expression *models.Expression) error {
+			if !*rescore && expression.Prediction != nil && expression.Prediction.Model == model.Version {
+				return nil
+			}
+
+			prediction := model.PredictInterval(dataset.Values(expression), *confidence)
+			expression.Prediction = &prediction
+			if _, err := expression.Update(); err != nil {
+				return err
+			}
+			scored++
+			return nil
+		})
+		if err != nil {
+			log.Fatal("Error scoring expressions ", err)
+		}
+		fmt.Println("Expressions scored: ", scored)
+	}
+
+	// Rows the model was fit on make it look better than it is, so every
+	// split is reported on its own
+	ratios, err := engagement.TrainingRatios(version)
+	if err != nil {
+		fmt.Println("Unknown training splits, assuming default ratios: ", err)
+		ratios = dataset.DefaultRatios
+	}
+
+	// Compare against the latest count of mature expressions, the ones the
+	// evaluator and the exporter use. Expressions collected before maturity
+	// was tagged were all mature.
+	query["prediction.model"] = model.Version
+	query["mature"] = database.Query{"$ne": false}
+
+	outcomes := map[string][]engagement.Outcome{}
+	err = dataset.Each(query, *batch, func(expression *models.Expression) error {
+		if expression.TotalInteraction == nil {
+			return nil
+		}
+
+		observed := *expression.TotalInteraction
+		if snapshot, err := models.GetLatestSnapshot(expression.PostID); err == nil {
+			observed = snapshot.TotalInteraction
+		}
+
+		split := dataset.SplitOf(expression.PostID, ratios)
+		outcomes[split] = append(outcomes[split], engagement.Outcome{
+			Prediction: *expression.Prediction,
+			Observed:   float64(observed),
+		})
+		return nil
+	})
+	if err != nil {
+		log.Fatal("Error reading predictions ", err)
+	}
+
+	for _, split := range []string{dataset.SplitTest, dataset.SplitValidation, dataset.SplitTrain} {
+		report := engagement.NewReport(model.Version, outcomes[split])
+		fmt.Printf("Report of %s on %d matured expressions of the %s split\n", report.Version, report.Log.Count, split)
+		if split == dataset.SplitTrain {
+			fmt.Println("  (optimistic, the model was fit on this split)")
+		}
+		printReport(report)
+	}
+}
+
+// printReport prints the metrics of report
+func printReport(report engagement.Report) {
+	fmt.Printf("  log1p:        MAE %.4f RMSE %.4f R2 %.4f Spearman %.4f\n",
+		report.Log.MAE, report.Log.RMSE, report.Log.R2, report.Log.Spearman)
+	fmt.Printf("  interactions: MAE %.2f RMSE %.2f R2 %.4f bias %+.2f\n",
+		report.Interaction.MAE, report.Interaction.RMSE, report.Interaction.R2, report.Bias)
+	fmt.Printf("  interval coverage: %.1f%%\n", report.Coverage*100)
+}
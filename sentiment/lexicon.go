@@ -0,0 +1,117 @@
+package sentiment
+
+// lexicon holds the valence of English words from -4, most negative, to 4,
+// most positive, on the scale of the VADER lexicon
+var lexicon = map[string]float64{
+	// positive
+	"amazing": 2.8, "awesome": 3.1, "beautiful": 2.9, "best": 3.2, "better": 1.9,
+	"blessed": 2.9, "brilliant": 2.8, "calm": 1.3, "celebrate": 2.7, "champion": 2.9,
+	"cheer": 2.3, "cheers": 2.1, "congrats": 2.4, "congratulations": 2.9, "cool": 1.3,
+	"cute": 2.0, "delight": 2.9, "delighted": 3.0, "delightful": 2.9, "easy": 1.9,
+	"enjoy": 2.2, "enjoyed": 2.3, "excellent": 2.7, "excited": 1.4, "exciting": 2.2,
+	"fabulous": 2.4, "fan": 1.3, "fantastic": 2.6, "favorite": 2.0, "favourite": 2.0,
+	"fine": 0.8, "free": 2.3, "fresh": 1.3, "friend": 2.2, "friendly": 2.2,
+	"fun": 2.3, "funny": 1.9, "glad": 2.0, "glorious": 3.2, "good": 1.9,
+	"gorgeous": 3.0, "grateful": 2.0, "great": 3.1, "happy": 2.7, "happiness": 2.6,
+	"heaven": 2.4, "helpful": 1.8, "hero": 2.6, "hilarious": 1.7, "honest": 2.3,
+	"hope": 1.9, "hopeful": 2.3, "hug": 2.1, "hugs": 2.1, "incredible": 2.4,
+	"inspiring": 2.7, "interesting": 1.7, "joy": 2.8, "kind": 2.4, "laugh": 2.6,
+	"like": 1.5, "liked": 1.8, "lol": 1.8, "love": 3.2, "loved": 2.9,
+	"lovely": 2.8, "loving": 2.9, "lucky": 1.8, "magnificent": 2.9, "nice": 1.8,
+	"peace": 2.5, "perfect": 2.7, "pleasant": 2.3, "please": 1.3, "pleased": 1.9,
+	"positive": 2.6, "pretty": 2.2, "proud": 2.1, "recommend": 1.5, "relaxed": 2.2,
+	"respect": 2.1, "rich": 2.6, "safe": 1.9, "smile": 1.5, "smiling": 2.4,
+	"special": 1.7, "strong": 2.3, "stunning": 2.7, "success": 2.7, "successful": 2.8,
+	"support": 1.7, "sweet": 2.0, "terrific": 2.1, "thank": 1.5,
+	"thanks": 1.9, "thankful": 2.7, "top": 0.8, "true": 1.8, "trust": 2.3,
+	"useful": 1.9, "victory": 2.8, "welcome": 2.0, "win": 2.8, "winner": 2.8,
+	"winning": 2.4, "wins": 2.7, "won": 2.7, "wonderful": 2.7, "wow": 2.8,
+	"yay": 2.4, "yes": 1.7,
+
+	// negative
+	"abuse": -3.2, "afraid": -2.2, "angry": -2.3, "annoyed": -1.6, "annoying": -1.7,
+	"anxious": -1.0, "arrest": -1.4, "ashamed": -2.1, "attack": -2.1, "awful": -2.0,
+	"bad": -2.5, "blame": -1.4, "bored": -1.1, "boring": -1.3, "broken": -2.1,
+	"bullshit": -2.8, "cheat": -2.0, "crap": -1.6, "crash": -1.7, "crazy": -1.4,
+	"crime": -2.5, "crisis": -3.1, "cruel": -2.8, "cry": -2.1, "crying": -2.1,
+	"damn": -1.7, "danger": -2.4, "dead": -3.3, "death": -2.9, "depressed": -2.3,
+	"destroy": -2.5, "disappointed": -1.9, "disappointing": -2.2, "disaster": -3.1, "disgusting": -2.4,
+	"dumb": -2.3, "evil": -3.4, "fail": -2.5, "failed": -2.3, "failure": -2.3,
+	"fake": -2.1, "fear": -2.2, "fight": -1.6, "fraud": -2.8, "frustrated": -2.4,
+	"fuck": -2.5, "hate": -2.7, "hated": -3.2, "hell": -3.6, "horrible": -2.5,
+	"hurt": -2.4, "idiot": -2.3, "ill": -1.8, "kill": -3.7, "killed": -3.5,
+	"lie": -1.6, "lies": -1.8, "lonely": -1.5, "lose": -1.7, "loser": -2.4,
+	"losing": -1.6, "loss": -1.3, "lost": -1.3, "mad": -2.2, "mess": -1.5,
+	"miss": -0.6, "mistake": -1.4, "nasty": -2.6, "pain": -2.3, "panic": -2.3,
+	"pathetic": -2.2, "poor": -2.1, "problem": -1.7, "racist": -3.1, "rude": -2.0,
+	"sad": -2.1, "scam": -2.2, "scared": -1.9, "shame": -2.1, "shit": -2.6,
+	"sick": -2.3, "sorry": -0.3, "stupid": -2.4, "suck": -1.9, "sucks": -1.5,
+	"suffer": -2.5, "terrible": -2.1, "terror": -3.0, "threat": -2.4, "tired": -1.9,
+	"trash": -1.5, "ugly": -2.3, "unfair": -2.1, "unhappy": -1.8, "upset": -1.6,
+	"useless": -1.8, "violence": -3.1, "war": -2.9, "waste": -1.8, "weak": -1.9,
+	"worried": -1.2, "worse": -2.1, "worst": -3.1, "wrong": -2.1, "wtf": -2.8,
+}
+
+// boosters scale the valence of the sentiment word they precede
+var boosters = map[string]float64{
+	"absolutely": boostIncrement, "amazingly": boostIncrement, "completely": boostIncrement,
+	"deeply": boostIncrement, "especially": boostIncrement, "extremely": boostIncrement,
+	"highly": boostIncrement, "incredibly": boostIncrement, "most": boostIncrement,
+	"really": boostIncrement, "so": boostIncrement, "super": boostIncrement,
+	"totally": boostIncrement, "truly": boostIncrement, "very": boostIncrement,
+	"almost": -boostIncrement, "barely": -boostIncrement, "hardly": -boostIncrement,
+	"less": -boostIncrement, "little": -boostIncrement, "marginally": -boostIncrement,
+	"occasionally": -boostIncrement, "partly": -boostIncrement, "scarcely": -boostIncrement,
+	"slightly": -boostIncrement, "somewhat": -boostIncrement, "sort": -boostIncrement,
+}
+
+// negations flip the valence of the sentiment words they precede, along
+// with every word ending in n't
+var negations = map[string]bool{
+	"ain't": true, "cannot": true, "neither": true, "never": true, "no": true,
+	"nobody": true, "none": true, "nope": true, "nor": true, "not": true,
+	"nothing": true, "nowhere": true, "without": true,
+}
+
+// emojiLexicon holds the valence of common emojis, skin tones and
+// variation selectors removed
+var emojiLexicon = map[string]float64{
+	"\U0001F602": 1.8,  // face with tears of joy
+	"\U0001F923": 1.8,  // rolling on the floor laughing
+	"\U0001F60D": 3.0,  // smiling face with heart eyes
+	"\U0001F60A": 2.2,  // smiling face with smiling eyes
+	"\U0001F600": 2.0,  // grinning face
+	"\U0001F601": 2.0,  // beaming face
+	"\U0001F603": 2.0,  // grinning face with big eyes
+	"\U0001F604": 2.2,  // grinning face with smiling eyes
+	"\U0001F642": 1.2,  // slightly smiling face
+	"\U0001F609": 1.4,  // winking face
+	"\U0001F618": 2.6,  // face blowing a kiss
+	"\U0001F970": 2.8,  // smiling face with hearts
+	"\U0001F929": 2.6,  // star struck
+	"\U0001F389": 2.4,  // party popper
+	"\U0001F44F": 1.8,  // clapping hands
+	"\U0001F44D": 1.8,  // thumbs up
+	"\U0001F64F": 1.2,  // folded hands
+	"\U0001F4AA": 1.6,  // flexed biceps
+	"\U0001F525": 1.4,  // fire
+	"\u2764":     3.0,  // red heart
+	"\U0001F495": 2.6,  // two hearts
+	"\U0001F499": 2.4,  // blue heart
+	"\U0001F49A": 2.4,  // green heart
+	"\U0001F49C": 2.4,  // purple heart
+	"\U0001F622": -2.0, // crying face
+	"\U0001F62D": -2.2, // loudly crying face
+	"\U0001F614": -1.6, // pensive face
+	"\U0001F61E": -1.8, // disappointed face
+	"\U0001F621": -2.6, // pouting face
+	"\U0001F620": -2.4, // angry face
+	"\U0001F92C": -2.8, // face with symbols on mouth
+	"\U0001F612": -1.4, // unamused face
+	"\U0001F644": -1.2, // face with rolling eyes
+	"\U0001F629": -1.8, // weary face
+	"\U0001F631": -2.0, // face screaming in fear
+	"\U0001F494": -2.6, // broken heart
+	"\U0001F44E": -1.8, // thumbs down
+	"\U0001F92E": -2.4, // face vomiting
+}
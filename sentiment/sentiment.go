@@ -0,0 +1,295 @@
+// Package sentiment scores the sentiment of tweet text offline with a
+// lexicon and the rules of VADER: intensifiers, negations, capitals, "but"
+// and punctuation emphasis.
+package sentiment
+
+import (
+	"html"
+	"math"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/thebigear/models"
+	"github.com/thebigear/normalizer"
+)
+
+// Name of the analyzer recorded on analyses
+const Name = "lexicon"
+
+// Version of the analyzer, bump it whenever the lexicon or the rules change
+// so stored analyses get recomputed
+const Version = 1
+
+// Rule weights of VADER
+const (
+	boostIncrement    = 0.293
+	capsIncrement     = 0.733
+	negationScalar    = -0.74
+	exclamationWeight = 0.292
+	questionWeight    = 0.18
+	normalizeAlpha    = 15
+)
+
+const (
+	variationSelector = '\ufe0f'
+	firstSkinTone     = '\U0001F3FB'
+	lastSkinTone      = '\U0001F3FF'
+)
+
+// Languages are the languages the lexicon covers
+var Languages = []string{"en"}
+
+// Supports reports whether the lexicon covers lang
+func Supports(lang string) bool {
+	for _, language := range Languages {
+		if language == lang {
+			return true
+		}
+	}
+	return false
+}
+
+// AnalyzeLang scores the sentiment of text in lang, leaving it unknown
+// for languages the lexicon does not cover rather than calling it neutral
+func AnalyzeLang(text string, lang string) *models.Analysis {
+	if !Supports(lang) {
+		return nil
+	}
+	return Analyze(text)
+}
+
+// Analyze scores the sentiment of text. Polarity is the normalized sum of
+// valences in [-1, 1], while positive, negative and neutral are the shares
+// of the text carrying each sentiment.
+func Analyze(text string) *models.Analysis {
+	text = strings.Replace(html.UnescapeString(text), "\u2019", "'", -1)
+	text, emojis := normalizer.ExtractEmojis(text)
+
+	words := tokenize(text)
+	mixedCase := isMixedCase(words)
+
+	sentiments := make([]float64, 0, len(words))
+	for i := range words {
+		sentiments = append(sentiments, valence(words, i, mixedCase))
+	}
+	sentiments = butCheck(words, sentiments)
+
+	for _, emoji := range emojis {
+		if value, ok := emojiLexicon[emojiBase(emoji.Emoji)]; ok {
+			for i := 0; i < emoji.Count; i++ {
+				sentiments = append(sentiments, value)
+			}
+		}
+	}
+
+	analysis := score(sentiments, punctuationEmphasis(text))
+	analysis.Analyzer = Name
+	analysis.Version = Version
+	analysis.AnalyzedAt = time.Now()
+	return analysis
+}
+
+// IsCurrent reports whether the analysis of expression was made by this
+// Version of the analyzer
+func IsCurrent(expression *models.Expression) bool {
+	return expression.Analysis != nil && expression.Analysis.Analyzer == Name &&
+		expression.Analysis.Version == Version
+}
+
+// tokenize splits text into words trimmed of surrounding punctuation,
+// leaving out links and mentions
+func tokenize(text string) []string {
+	var words []string
+	for _, field := range strings.Fields(text) {
+		if strings.HasPrefix(field, "http") || strings.HasPrefix(field, "@") {
+			continue
+		}
+
+		word := strings.TrimFunc(field, func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsNumber(r) && r != '\''
+		})
+		word = strings.Trim(word, "'")
+		if word != "" {
+			words = append(words, word)
+		}
+	}
+	return words
+}
+
+// valence is the sentiment of the word at i adjusted by the capitals,
+// boosters and negations around it
+func valence(words []string, i int, mixedCase bool) float64 {
+	word := words[i]
+	lower := strings.ToLower(word)
+
+	if _, ok := boosters[lower]; ok {
+		return 0
+	}
+	value, ok := lexicon[lower]
+	if !ok {
+		return 0
+	}
+
+	if mixedCase && isUpper(word) {
+		value += math.Copysign(capsIncrement, value)
+	}
+
+	// Boosters fade with their distance from the word
+	decay := []float64{1, 0.95, 0.9}
+	for distance := 1; distance <= 3 && i-distance >= 0; distance++ {
+		value += boost(words[i-distance], value, mixedCase) * decay[distance-1]
+	}
+
+	for distance := 1; distance <= 3 && i-distance >= 0; distance++ {
+		if isNegation(words[i-distance]) {
+			value *= negationScalar
+			break
+		}
+	}
+
+	return value
+}
+
+// boost is what word adds to a following sentiment of given value
+func boost(word string, value float64, mixedCase bool) float64 {
+	scalar, ok := boosters[strings.ToLower(word)]
+	if !ok {
+		return 0
+	}
+
+	if value < 0 {
+		scalar = -scalar
+	}
+	if mixedCase && isUpper(word) {
+		scalar += math.Copysign(capsIncrement, value)
+	}
+	return scalar
+}
+
+// butCheck weakens sentiments before "but" and strengthens the ones after
+func butCheck(words []string, sentiments []float64) []float64 {
+	but := -1
+	for i, word := range words {
+		if strings.ToLower(word) == "but" {
+			but = i
+			break
+		}
+	}
+	if but < 0 {
+		return sentiments
+	}
+
+	for i := range sentiments {
+		if i < but {
+			sentiments[i] *= 0.5
+		} else if i > but {
+			sentiments[i] *= 1.5
+		}
+	}
+	return sentiments
+}
+
+// punctuationEmphasis is how much exclamation and question marks amplify
+// the sentiment of text
+func punctuationEmphasis(text string) float64 {
+	exclamations := strings.Count(text, "!")
+	if exclamations > 4 {
+		exclamations = 4
+	}
+
+	emphasis := float64(exclamations) * exclamationWeight
+	if questions := strings.Count(text, "?"); questions > 1 {
+		if questions <= 3 {
+			emphasis += float64(questions) * questionWeight
+		} else {
+			emphasis += 0.96
+		}
+	}
+	return emphasis
+}
+
+// score turns the sentiments of a text into an analysis
+func score(sentiments []float64, emphasis float64) *models.Analysis {
+	analysis := &models.Analysis{}
+	if len(sentiments) == 0 {
+		return analysis
+	}
+
+	sum := 0.0
+	positive := 0.0
+	negative := 0.0
+	neutral := 0.0
+	for _, sentiment := range sentiments {
+		sum += sentiment
+		switch {
+		case sentiment > 0:
+			positive += sentiment + 1
+		case sentiment < 0:
+			negative += sentiment - 1
+		default:
+			neutral++
+		}
+	}
+
+	if sum > 0 {
+		sum += emphasis
+	} else if sum < 0 {
+		sum -= emphasis
+	}
+	analysis.Polarity = sum / math.Sqrt(sum*sum+normalizeAlpha)
+
+	if positive > math.Abs(negative) {
+		positive += emphasis
+	} else if positive < math.Abs(negative) {
+		negative -= emphasis
+	}
+
+	total := positive + math.Abs(negative) + neutral
+	analysis.Positive = positive / total
+	analysis.Negative = math.Abs(negative) / total
+	analysis.Neutral = neutral / total
+	return analysis
+}
+
+// isNegation reports whether word negates what follows it
+func isNegation(word string) bool {
+	lower := strings.ToLower(word)
+	return negations[lower] || strings.HasSuffix(lower, "n't")
+}
+
+// isUpper reports whether word is written in capitals
+func isUpper(word string) bool {
+	letters := 0
+	for _, r := range word {
+		if unicode.IsLower(r) {
+			return false
+		}
+		if unicode.IsUpper(r) {
+			letters++
+		}
+	}
+	return letters > 1
+}
+
+// isMixedCase reports whether only some of words are in capitals, which is
+// when capitals are emphasis rather than style
+func isMixedCase(words []string) bool {
+	upper := 0
+	for _, word := range words {
+		if isUpper(word) {
+			upper++
+		}
+	}
+	return upper > 0 && upper < len(words)
+}
+
+// emojiBase strips variation selectors and skin tones from emoji
+func emojiBase(emoji string) string {
+	return strings.Map(func(r rune) rune {
+		if r == variationSelector || (r >= firstSkinTone && r <= lastSkinTone) {
+			return -1
+		}
+		return r
+	}, emoji)
+}
@@ -0,0 +1,27 @@
+package sentiment
+
+import "testing"
+
+func TestAnalyzeLang(t *testing.T) {
+	tests := []struct {
+		text     string
+		lang     string
+		analyzed bool
+	}{
+		{text: "I love this, great day!", lang: "en", analyzed: true},
+		{text: "Bugün harika bir gün", lang: "tr"},
+		{text: "I love this", lang: ""},
+		{text: "I love this", lang: "und"},
+	}
+
+	for _, test := range tests {
+		analysis := AnalyzeLang(test.text, test.lang)
+		if (analysis != nil) != test.analyzed {
+			t.Errorf("AnalyzeLang(%q, %q) = %+v, want analyzed %v", test.text, test.lang, analysis, test.analyzed)
+		}
+	}
+
+	if analysis := AnalyzeLang("I love this, great day!", "en"); analysis.Polarity <= 0 || analysis.Analyzer != Name {
+		t.Errorf("English analysis = %+v, want a positive polarity by %s", analysis, Name)
+	}
+}
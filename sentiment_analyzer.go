@@ -0,0 +1,64 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+
+	"github.com/joho/godotenv"
+	"github.com/thebigear/database"
+	"github.com/thebigear/dataset"
+	"github.com/thebigear/models"
+	"github.com/thebigear/sentiment"
+	"github.com/tuvistavie/structomap"
+)
+
+func init() {
+	database.Connect()
+	database.EnsureIndexes()
+	// Use snake case in all serializers
+	structomap.SetDefaultCase(structomap.SnakeCase)
+	err := godotenv.Load()
+	if err != nil {
+		log.Fatal("Error loading .env file", err)
+	}
+}
+
+func main() {
+	all := flag.Bool("all", false, "Analyze every expression again, not only unanalyzed or outdated ones")
+	batch := flag.Int("batch", 500, "Expressions loaded at a time")
+
+	flag.Parse()
+
+	fmt.Println("analyzer:", sentiment.Name)
+	fmt.Println("version:", sentiment.Version)
+	fmt.Println("all:", *all)
+
+	// The lexicon is English, scores of other languages only look neutral
+	unsupported := database.Query{}
+	unsupported["lang"] = database.Query{"$nin": sentiment.Languages}
+	unsupported["analysis"] = database.Query{"$exists": true}
+	cleared, err := database.Mongo.UpdateAll(models.DBTableExpressions, unsupported, database.Query{"$unset": database.Query{"analysis": ""}})
+	if err != nil {
+		log.Fatal("Error clearing analyses ", err)
+	}
+	fmt.Println("Analyses cleared: ", cleared)
+
+	query := database.Query{}
+	query["deleted_at"] = nil
+	query["lang"] = database.Query{"$in": sentiment.Languages}
+	if !*all {
+		query["$or"] = []database.Query{
+			{"analysis.analyzer": database.Query{"$ne": sentiment.Name}},
+			{"analysis.version": database.Query{"$ne": sentiment.Version}},
+		}
+	}
+
+	analyzed, err := dataset.Backfill(query, *batch, func(expression *models.Expression) {
+		expression.Analysis = sentiment.AnalyzeLang(expression.FullText, expression.Lang)
+	})
+	if err != nil {
+		log.Fatal("Error analyzing expressions ", err)
+	}
+	fmt.Println("Expressions analyzed: ", analyzed)
+}
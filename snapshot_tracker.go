@@ -0,0 +1,69 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/joho/godotenv"
+	"github.com/thebigear/database"
+	"github.com/thebigear/twitterear"
+	"github.com/tuvistavie/structomap"
+)
+
+func init() {
+	database.Connect()
+	database.EnsureIndexes()
+	// Use snake case in all serializers
+	structomap.SetDefaultCase(structomap.SnakeCase)
+	err := godotenv.Load()
+	if err != nil {
+		log.Fatal("Error loading .env file", err)
+	}
+}
+
+func main() {
+	scheduleFlag := flag.String("schedule", "1h,6h,1d,3d,7d", "Snapshot steps after collection")
+	interval := flag.Duration("interval", 10*time.Minute, "Time between tracker runs")
+	once := flag.Bool("once", false, "Run a single pass and exit")
+	replay := flag.String("replay", "", "Comma separated JSONL recordings to replay instead of calling Twitter")
+
+	flag.Parse()
+
+	schedule, err := twitterear.ParseSchedule(*scheduleFlag)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	fmt.Println("schedule:", schedule)
+	fmt.Println("interval:", *interval)
+
+	var source twitterear.TweetSource
+	if *replay != "" {
+		source, err = twitterear.NewReplaySource(twitterear.SplitKeywords(*replay)...)
+		if err != nil {
+			log.Fatal("Error loading recordings ", err)
+		}
+	} else {
+		source = twitterear.NewLiveSource(twitterear.NewTwitterClient())
+	}
+
+	tracker := twitterear.NewSnapshotTracker(source, schedule)
+	if err := tracker.Validate(); err != nil {
+		log.Fatal(err)
+	}
+
+	for {
+		taken, err := tracker.Run()
+		if err != nil {
+			fmt.Println("Snapshot run failed: ", err)
+		}
+		fmt.Println("Snapshots taken: ", taken)
+
+		if *once {
+			return
+		}
+		time.Sleep(*interval)
+	}
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/joho/godotenv"
+	"github.com/thebigear/database"
+	"github.com/thebigear/dataset"
+	"github.com/thebigear/engagement"
+	"github.com/tuvistavie/structomap"
+)
+
+func init() {
+	database.Connect()
+	database.EnsureIndexes()
+	// Use snake case in all serializers
+	structomap.SetDefaultCase(structomap.SnakeCase)
+	err := godotenv.Load()
+	if err != nil {
+		log.Fatal("Error loading .env file", err)
+	}
+}
+
+func main() {
+	kind := flag.String("model", "all", "Model to train: ridge, gbt or all")
+	out := flag.String("out", "engagement_models", "Directory models are saved to as <model>.json")
+	ratiosFlag := flag.String("ratios", "0.8,0.1,0.1", "Train, validation and test shares")
+	lang := flag.String("lang", "", "Only train on expressions in this language")
+	lambda := flag.Float64("lambda", engagement.DefaultParams.Lambda, "Ridge penalty")
+	trees := flag.Int("trees", engagement.DefaultParams.GBT.Trees, "Boosted trees")
+	depth := flag.Int("depth", engagement.DefaultParams.GBT.Depth, "Depth of boosted trees")
+	rate := flag.Float64("rate", engagement.DefaultParams.GBT.LearningRate, "Learning rate of boosting")
+	minLeaf := flag.Int("min-leaf", engagement.DefaultParams.GBT.MinLeaf, "Fewest rows in a tree leaf")
+	register := flag.Bool("register", true, "Record trained models in the model registry")
+	activate := flag.String("activate", "", "Make the trained ridge or gbt model the active version")
+
+	flag.Parse()
+
+	kinds := []string{*kind}
+	if *kind == "all" {
+		kinds = []string{engagement.KindRidge, engagement.KindGBT}
+	}
+
+	ratios, err := dataset.ParseRatios(*ratiosFlag)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	params := engagement.Params{
+		Lambda: *lambda,
+		GBT: engagement.GBTParams{
+			Trees:        *trees,
+			Depth:        *depth,
+			LearningRate: *rate,
+			MinLeaf:      *minLeaf,
+		},
+	}
+
+	query := database.Query{}
+	query["deleted_at"] = nil
+	query["total_interaction"] = database.Query{"$exists": true}
+	// Expressions collected before maturity was tagged were all mature
+	query["mature"] = database.Query{"$ne": false}
+	if *lang != "" {
+		query["lang"] = *lang
+	}
+
+	rows, err := dataset.Load(query, ratios, "")
+	if err != nil {
+		log.Fatal("Error loading rows ", err)
+	}
+	splits := dataset.BySplit(rows)
+	train := splits[dataset.SplitTrain]
+	validation := splits[dataset.SplitValidation]
+	fmt.Println("train rows:", len(train))
+	fmt.Println("validation rows:", len(validation))
+
+	// Prediction intervals are as wide as the validation RMSE, a model
+	// without validation rows would claim to be exact
+	if len(validation) == 0 {
+		log.Fatal("No validation rows, collect more expressions or raise the validation ratio")
+	}
+
+	datasetQuery, err := json.Marshal(map[string]interface{}{
+		"query":  query,
+		"ratios": ratios,
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if err := os.MkdirAll(*out, 0755); err != nil {
+		log.Fatal("Error creating model directory ", err)
+	}
+
+	for _, kind := range kinds {
+		model, err := engagement.Train(kind, train, params)
+		if err != nil {
+			log.Fatal("Error training ", kind, " ", err)
+		}
+		model.Metrics = model.Evaluate(validation)
+
+		path := filepath.Join(*out, kind+".json")
+		if err := model.Save(path); err != nil {
+			log.Fatal("Error saving ", kind, " ", err)
+		}
+
+		fmt.Printf("%s saved to %s, validation MAE %.4f RMSE %.4f R2 %.4f Spearman %.4f\n",
+			model.Version, path, model.Metrics.MAE, model.Metrics.RMSE, model.Metrics.R2, model.Metrics.Spearman)
+
+		if !*register {
+			continue
+		}
+		version, err := engagement.Register(model, path, string(datasetQuery))
+		if err != nil {
+			log.Fatal("Error registering ", model.Version, " ", err)
+		}
+		if *activate == kind {
+			if err := version.Activate(); err != nil {
+				log.Fatal("Error activating ", model.Version, " ", err)
+			}
+			fmt.Println("Activated", model.Version)
+		}
+	}
+}
@@ -0,0 +1,247 @@
+package twitterear
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/thebigear/inclusion"
+	"github.com/thebigear/models"
+	"gopkg.in/yaml.v2"
+)
+
+// Campaign is a named set of searches collected on a schedule, every
+// expression it stores is tagged with its ID
+type Campaign struct {
+	ID      string   `json:"id" yaml:"id"`
+	Queries []string `json:"queries" yaml:"queries"`
+	// Languages are searched one by one, empty searches all languages
+	Languages  []string `json:"languages" yaml:"languages"`
+	ResultType string   `json:"result_type" yaml:"result_type"`
+	// Every is the time between runs like "6h" or "1d", empty runs the
+	// campaign on every pass of the runner
+	Every string `json:"every" yaml:"every"`
+	// Count is the page size and Quota the most tweets collected per query
+	// and language in a run
+	Count int `json:"count" yaml:"count"`
+	Quota int `json:"quota" yaml:"quota"`
+	// Since, Until and MinAge bound the window like the collector flags,
+	// MinAge defaults to DefaultMaturityAge
+	Since    string     `json:"since" yaml:"since"`
+	Until    string     `json:"until" yaml:"until"`
+	MinAge   string     `json:"min_age" yaml:"min_age"`
+	Enrich   Enrichment `json:"enrich" yaml:"enrich"`
+	Disabled bool       `json:"disabled" yaml:"disabled"`
+
+	// Interval and Window are parsed from the fields above by Validate
+	Interval time.Duration `json:"-" yaml:"-"`
+	Window   SearchWindow  `json:"-" yaml:"-"`
+}
+
+// Enrichment configures how the tweets of a campaign are enriched, empty
+// fields fall back to the environment of NewCollector
+type Enrichment struct {
+	// Labeler is an image labeler kind, see NewImageLabeler
+	Labeler string `json:"labeler" yaml:"labeler"`
+	// Clean is an entity policy like the CLEAN_POLICY variable
+	Clean string `json:"clean" yaml:"clean"`
+	// HistorySize is how many earlier tweets of authors are summarized, 0
+	// skips author timelines
+	HistorySize *int `json:"history_size" yaml:"history_size"`
+	// Rules is a JSON inclusion rules file
+	Rules string `json:"rules" yaml:"rules"`
+}
+
+// Campaigns is the content of a campaign file
+type Campaigns struct {
+	Campaigns []*Campaign `json:"campaigns" yaml:"campaigns"`
+}
+
+// LoadCampaigns reads and validates the campaign file at path, YAML when
+// it ends in .yaml or .yml and JSON otherwise
+func LoadCampaigns(path string) (*Campaigns, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	campaigns := &Campaigns{}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, campaigns)
+	default:
+		err = json.Unmarshal(data, campaigns)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+
+	seen := map[string]bool{}
+	for _, campaign := range campaigns.Campaigns {
+		if err := campaign.Validate(); err != nil {
+			return nil, err
+		}
+		if seen[campaign.ID] {
+			return nil, fmt.Errorf("duplicate campaign %q", campaign.ID)
+		}
+		seen[campaign.ID] = true
+	}
+
+	return campaigns, nil
+}
+
+// Get returns the campaign with given ID, nil when there is none
+func (campaigns *Campaigns) Get(id string) *Campaign {
+	for _, campaign := range campaigns.Campaigns {
+		if campaign.ID == id {
+			return campaign
+		}
+	}
+	return nil
+}
+
+// Validate checks the campaign, fills in defaults and parses its schedule
+// and window
+func (campaign *Campaign) Validate() error {
+	if campaign.ID == "" {
+		return fmt.Errorf("campaign without id")
+	}
+	if len(campaign.Queries) == 0 {
+		return fmt.Errorf("campaign %q has no queries", campaign.ID)
+	}
+
+	switch campaign.ResultType {
+	case "":
+		campaign.ResultType = ResultTypeMixed
+	case ResultTypeMixed, ResultTypePopular, ResultTypeRecent:
+	default:
+		return fmt.Errorf("campaign %q has unknown result type %q", campaign.ID, campaign.ResultType)
+	}
+
+	if campaign.Count <= 0 {
+		campaign.Count = 100
+	}
+	if campaign.Quota <= 0 {
+		campaign.Quota = campaign.Count
+	}
+
+	if campaign.Every != "" {
+		schedule, err := ParseSchedule(campaign.Every)
+		if err != nil || len(schedule) != 1 {
+			return fmt.Errorf("campaign %q has invalid schedule %q", campaign.ID, campaign.Every)
+		}
+		campaign.Interval = schedule[0]
+	}
+
+	var err error
+	campaign.Window = DefaultSearchWindow
+	if campaign.MinAge != "" {
+		if campaign.Window.MinAge, err = time.ParseDuration(campaign.MinAge); err != nil {
+			return fmt.Errorf("campaign %q has invalid min age %q", campaign.ID, campaign.MinAge)
+		}
+	}
+	if campaign.Window.Since, err = ParseWindowTime(campaign.Since); err != nil {
+		return fmt.Errorf("campaign %q: %v", campaign.ID, err)
+	}
+	if campaign.Window.Until, err = ParseWindowTime(campaign.Until); err != nil {
+		return fmt.Errorf("campaign %q: %v", campaign.ID, err)
+	}
+
+	if _, err := ParseCleanPolicy(campaign.Enrich.Clean); err != nil {
+		return fmt.Errorf("campaign %q: %v", campaign.ID, err)
+	}
+
+	return nil
+}
+
+// Due reports whether the campaign should run at now given its last run,
+// nil when it never ran
+func (campaign *Campaign) Due(last *models.CollectionRun, now time.Time) bool {
+	if campaign.Disabled {
+		return false
+	}
+	if last == nil || campaign.Interval == 0 {
+		return true
+	}
+	return !now.Before(last.StartedAt.Add(campaign.Interval))
+}
+
+// NewCollector creates a Collector reading from source with the enrichment
+// options of the campaign, tagging expressions with its ID
+func (campaign *Campaign) NewCollector(source TweetSource) (*Collector, error) {
+	labeler, err := NewImageLabeler(campaign.Enrich.Labeler)
+	if err != nil {
+		return nil, err
+	}
+
+	collector := NewCollector(source, labeler)
+	collector.Campaign = campaign.ID
+
+	if campaign.Enrich.Clean != "" {
+		if collector.CleanPolicy, err = ParseCleanPolicy(campaign.Enrich.Clean); err != nil {
+			return nil, err
+		}
+	}
+	if campaign.Enrich.HistorySize != nil {
+		collector.HistorySize = *campaign.Enrich.HistorySize
+	}
+	if campaign.Enrich.Rules != "" {
+		if collector.Rules, err = inclusion.LoadRules(campaign.Enrich.Rules); err != nil {
+			return nil, err
+		}
+	}
+	// Without rules of its own a campaign of fresh tweets is not judged on
+	// their interactions
+	collector.SetMinAge(campaign.Window.MinAge)
+
+	return collector, nil
+}
+
+// Run searches every query of the campaign in each of its languages up to
+// its quota with collector and stores a run for each of them. Checkpoints
+// are kept per campaign, so campaigns sharing a query page on their own;
+// fresh runs start from scratch and leave them alone.
+func (campaign *Campaign) Run(collector *Collector, fresh bool) {
+	languages := campaign.Languages
+	if len(languages) == 0 {
+		languages = []string{""}
+	}
+
+	mode := "campaign"
+	if fresh {
+		mode = "replay"
+	}
+
+	for _, query := range campaign.Queries {
+		for _, language := range languages {
+			startedAt := time.Now()
+			collector.Stats = models.NewInclusionStats()
+
+			key := campaign.ID + "/" + query
+			checkpoint, err := models.GetSearchCheckpoint(key, language)
+			if err != nil || fresh {
+				checkpoint = &models.SearchCheckpoint{Key: key, Lang: language}
+			}
+
+			tweets := GetTweetsFromSearchApi(collector.Source, &query, &campaign.Count, &campaign.Quota, campaign.ResultType, campaign.Window, checkpoint)
+			fmt.Printf("Campaign %s collected %d tweets of %q in %q\n", campaign.ID, len(tweets), query, language)
+
+			for _, tweet := range tweets {
+				if _, err := collector.Collect(tweet); err != nil {
+					fmt.Println("Error storing tweet ", tweet.ID, err)
+				}
+			}
+
+			if !fresh {
+				if _, err := checkpoint.Save(); err != nil {
+					fmt.Println("Error saving search checkpoint ", err)
+				}
+			}
+
+			collector.SaveRun(&models.CollectionRun{Key: key, Lang: language, Mode: mode, StartedAt: startedAt})
+		}
+	}
+}
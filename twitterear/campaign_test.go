@@ -0,0 +1,75 @@
+package twitterear
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestLoadCampaigns(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+
+	files := map[string]string{
+		"campaigns.json": `{"campaigns": [{"id": "elections", "queries": ["vote", "#election"], "languages": ["en", "tr"],
+			"result_type": "popular", "every": "6h", "count": 100, "quota": 300, "since": "2026-10-01", "min_age": "0",
+			"enrich": {"labeler": "fake", "clean": "urls=drop", "history_size": 0}}]}`,
+		// Dates and numbers are left unquoted
+		"campaigns.yaml": `
+campaigns:
+  - id: elections
+    queries: [vote, "#election"]
+    languages: [en, tr]
+    result_type: popular
+    every: 6h
+    count: 100
+    quota: 300
+    since: 2026-10-01
+    min_age: 0
+    enrich:
+      labeler: fake
+      clean: urls=drop
+      history_size: 0
+`,
+		"broken.yml":     "campaigns:\n  - id: [",
+		"invalid.yml":    "campaigns:\n  - id: elections\n    result_type: oldest\n    queries: [vote]\n",
+		"duplicate.json": `{"campaigns": [{"id": "a", "queries": ["vote"]}, {"id": "a", "queries": ["poll"]}]}`,
+	}
+	for name, content := range files {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	fromJSON, err := LoadCampaigns(filepath.Join(dir, "campaigns.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	fromYAML, err := LoadCampaigns(filepath.Join(dir, "campaigns.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(fromJSON, fromYAML) {
+		t.Errorf("YAML campaigns %+v differ from JSON %+v", fromYAML.Campaigns[0], fromJSON.Campaigns[0])
+	}
+
+	campaign := fromYAML.Get("elections")
+	if campaign == nil {
+		t.Fatal("campaign elections not loaded")
+	}
+	if campaign.Interval != 6*time.Hour || campaign.Window.MinAge != 0 || campaign.Window.Since.IsZero() {
+		t.Errorf("interval %s and window %+v, want 6h from 2026-10-01 with no min age", campaign.Interval, campaign.Window)
+	}
+	if campaign.Enrich.HistorySize == nil || *campaign.Enrich.HistorySize != 0 {
+		t.Errorf("history size = %v, want 0", campaign.Enrich.HistorySize)
+	}
+
+	for _, name := range []string{"broken.yml", "invalid.yml", "duplicate.json", "missing.yaml"} {
+		if _, err := LoadCampaigns(filepath.Join(dir, name)); err == nil {
+			t.Errorf("loaded %s", name)
+		}
+	}
+}
@@ -0,0 +1,283 @@
+package twitterear
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/dghubble/go-twitter/twitter"
+	"github.com/thebigear/models"
+	"github.com/thebigear/normalizer"
+	"github.com/thebigear/utils"
+	"mvdan.cc/xurls"
+)
+
+// TokenPolicy decides what becomes of an entity in clean text
+type TokenPolicy string
+
+// Token policies
+const (
+	// PolicyDrop removes the entity
+	PolicyDrop TokenPolicy = "drop"
+	// PolicyKeep keeps the entity without its sigil: the hashtag text, the
+	// mentioned screen name or the displayed url
+	PolicyKeep TokenPolicy = "keep"
+	// PolicyReplace puts the placeholder token of the entity in its place
+	PolicyReplace TokenPolicy = "replace"
+)
+
+// Placeholder tokens of entities
+const (
+	HashtagToken = "<HASHTAG>"
+	UserToken    = "<USER>"
+	URLToken     = "<URL>"
+	MediaToken   = "<MEDIA>"
+)
+
+// CleanPolicy has a TokenPolicy for each kind of entity, and for the
+// stopwords of the tweet language which can only be dropped or kept
+type CleanPolicy struct {
+	Hashtags  TokenPolicy
+	Mentions  TokenPolicy
+	URLs      TokenPolicy
+	Media     TokenPolicy
+	Stopwords TokenPolicy
+}
+
+// DefaultCleanPolicy drops every entity and keeps stopwords
+var DefaultCleanPolicy = CleanPolicy{
+	Hashtags:  PolicyDrop,
+	Mentions:  PolicyDrop,
+	URLs:      PolicyDrop,
+	Media:     PolicyDrop,
+	Stopwords: PolicyKeep,
+}
+
+// ParseCleanPolicy parses policies like "hashtags=keep,mentions=replace,
+// stopwords=drop" on top of DefaultCleanPolicy
+func ParseCleanPolicy(value string) (CleanPolicy, error) {
+	policy := DefaultCleanPolicy
+
+	for _, element := range SplitKeywords(value) {
+		parts := strings.SplitN(element, "=", 2)
+		if len(parts) != 2 {
+			return policy, fmt.Errorf("invalid clean policy %q", element)
+		}
+
+		tokenPolicy := TokenPolicy(strings.TrimSpace(parts[1]))
+		switch tokenPolicy {
+		case PolicyDrop, PolicyKeep, PolicyReplace:
+		default:
+			return policy, fmt.Errorf("invalid token policy %q", parts[1])
+		}
+
+		switch strings.TrimSpace(parts[0]) {
+		case "hashtags":
+			policy.Hashtags = tokenPolicy
+		case "mentions":
+			policy.Mentions = tokenPolicy
+		case "urls":
+			policy.URLs = tokenPolicy
+		case "media":
+			policy.Media = tokenPolicy
+		case "stopwords":
+			if tokenPolicy == PolicyReplace {
+				return policy, fmt.Errorf("stopwords can not be replaced")
+			}
+			policy.Stopwords = tokenPolicy
+		default:
+			return policy, fmt.Errorf("invalid entity %q", parts[0])
+		}
+	}
+
+	return policy, nil
+}
+
+// CleanPolicyFromEnv returns the CLEAN_POLICY policies, DefaultCleanPolicy
+// when it is not set or invalid
+func CleanPolicyFromEnv() CleanPolicy {
+	policy, err := ParseCleanPolicy(utils.GetEnvOrDefault("CLEAN_POLICY", ""))
+	if err != nil {
+		fmt.Println("Error parsing CLEAN_POLICY ", err)
+		return DefaultCleanPolicy
+	}
+	return policy
+}
+
+// whitespaceReg matches runs of spaces
+var whitespaceReg = regexp.MustCompile("[ ]{2,}")
+
+// entitySpan is the rune range of an entity in tweet text and what it is
+// replaced with
+type entitySpan struct {
+	start       int
+	end         int
+	replacement string
+}
+
+// CleanTweet cleans tweet text with DefaultCleanPolicy
+func CleanTweet(tweet twitter.Tweet) string {
+	text, _ := CleanTweetWithPolicy(tweet, DefaultCleanPolicy)
+	return text
+}
+
+// CleanTweetWithPolicy replaces the entities of tweet text, located by
+// their indices, according to policy and normalizes the rest with the
+// rules of the tweet language. Emojis are returned apart from the clean
+// text.
+func CleanTweetWithPolicy(tweet twitter.Tweet, policy CleanPolicy) (string, models.Emojis) {
+	text, entities, media := tweetTextAndEntities(tweet)
+	// Twitter escapes the text but counts entity indices on the unescaped one
+	text = UnescapeTweetText(text)
+	runes := []rune(text)
+
+	var spans []entitySpan
+	add := func(indices twitter.Indices, tokenPolicy TokenPolicy, kept string, token string) {
+		if indices.Start() < 0 || indices.End() > len(runes) || indices.Start() >= indices.End() {
+			return
+		}
+
+		span := entitySpan{start: indices.Start(), end: indices.End()}
+		switch tokenPolicy {
+		case PolicyKeep:
+			span.replacement = normalizer.FoldLang(kept, tweet.Lang)
+		case PolicyReplace:
+			span.replacement = token
+		}
+		spans = append(spans, span)
+	}
+
+	if entities != nil {
+		for _, hashtag := range entities.Hashtags {
+			add(hashtag.Indices, policy.Hashtags, hashtag.Text, HashtagToken)
+		}
+		for _, mention := range entities.UserMentions {
+			add(mention.Indices, policy.Mentions, mention.ScreenName, UserToken)
+		}
+		for _, url := range entities.Urls {
+			add(url.Indices, policy.URLs, url.DisplayURL, URLToken)
+		}
+	}
+	for _, item := range media {
+		add(item.Indices, policy.Media, item.DisplayURL, MediaToken)
+	}
+
+	sort.Slice(spans, func(i, j int) bool {
+		return spans[i].start < spans[j].start
+	})
+
+	var parts []string
+	position := 0
+	for _, span := range spans {
+		// Every photo of a tweet points at the same url, skip overlaps
+		if span.start < position {
+			continue
+		}
+
+		parts = append(parts, cleanSegment(string(runes[position:span.start]), tweet.Lang, policy))
+		if span.replacement != "" {
+			parts = append(parts, " "+span.replacement+" ")
+		}
+		position = span.end
+	}
+	parts = append(parts, cleanSegment(string(runes[position:]), tweet.Lang, policy))
+
+	clean := whitespaceReg.ReplaceAllString(strings.Join(parts, ""), " ")
+	if policy.Stopwords == PolicyDrop {
+		clean = normalizer.RemoveStopwords(clean, tweet.Lang)
+	}
+
+	return strings.TrimSpace(clean), normalizer.Emojis(text)
+}
+
+// textEntityReg matches the hashtags and mentions of a text, not glued to
+// a preceding word
+var textEntityReg = regexp.MustCompile(`(?:^|[^\p{L}\p{N}_&])([#\x{FF03}][\p{L}\p{M}\p{N}_]+|[@\x{FF20}][A-Za-z0-9_]{1,15})`)
+
+// CleanTextWithPolicy cleans text stored without its entities, like the
+// escaped full text of an expression, the way CleanTweetWithPolicy cleans a
+// tweet. Hashtags and mentions are located in the text, urls are all
+// handled by the url policy.
+func CleanTextWithPolicy(text string, lang string, policy CleanPolicy) (string, models.Emojis) {
+	return CleanTweetWithPolicy(twitter.Tweet{FullText: text, Lang: lang, Entities: TextEntities(UnescapeTweetText(text))}, policy)
+}
+
+// tweetEscaper and tweetUnescaper escape and unescape the characters
+// Twitter escapes in tweet text
+var (
+	tweetEscaper   = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+	tweetUnescaper = strings.NewReplacer("&amp;", "&", "&lt;", "<", "&gt;", ">")
+)
+
+// EscapeTweetText escapes text like Twitter escapes tweet text
+func EscapeTweetText(text string) string {
+	return tweetEscaper.Replace(text)
+}
+
+// UnescapeTweetText undoes the escaping of tweet text, entity indices
+// point into the unescaped text
+func UnescapeTweetText(text string) string {
+	return tweetUnescaper.Replace(text)
+}
+
+// TextEntities locates the hashtags and mentions of text, for text that
+// did not come from Twitter with its entities
+func TextEntities(text string) *twitter.Entities {
+	entities := &twitter.Entities{}
+	for _, match := range textEntityReg.FindAllStringSubmatchIndex(text, -1) {
+		start := utf8.RuneCountInString(text[:match[2]])
+		entity := []rune(text[match[2]:match[3]])
+		indices := twitter.Indices{start, start + len(entity)}
+
+		if entity[0] == '#' || entity[0] == '\uff03' {
+			entities.Hashtags = append(entities.Hashtags, twitter.HashtagEntity{Text: string(entity[1:]), Indices: indices})
+		} else {
+			entities.UserMentions = append(entities.UserMentions, twitter.MentionEntity{ScreenName: string(entity[1:]), Indices: indices})
+		}
+	}
+
+	return entities
+}
+
+// tweetTextAndEntities returns the full text of tweet with the entities
+// and media whose indices point into it
+func tweetTextAndEntities(tweet twitter.Tweet) (string, *twitter.Entities, []twitter.MediaEntity) {
+	if extended := tweet.ExtendedTweet; extended != nil && extended.FullText != "" {
+		var media []twitter.MediaEntity
+		if extended.ExtendedEntities != nil {
+			media = extended.ExtendedEntities.Media
+		} else if extended.Entities != nil {
+			media = extended.Entities.Media
+		}
+		return extended.FullText, extended.Entities, media
+	}
+
+	text := tweet.FullText
+	if text == "" {
+		text = tweet.Text
+	}
+	return text, tweet.Entities, TweetMedia(tweet)
+}
+
+// cleanSegment normalizes text found between entities in lang. Urls
+// missing from the entities are handled by the url policy.
+func cleanSegment(text string, lang string, policy CleanPolicy) string {
+	var parts []string
+
+	position := 0
+	for _, match := range xurls.Relaxed().FindAllStringIndex(text, -1) {
+		parts = append(parts, normalizer.NormalizeLang(text[position:match[0]], lang))
+		switch policy.URLs {
+		case PolicyKeep:
+			parts = append(parts, text[match[0]:match[1]])
+		case PolicyReplace:
+			parts = append(parts, URLToken)
+		}
+		position = match[1]
+	}
+	parts = append(parts, normalizer.NormalizeLang(text[position:], lang))
+
+	return " " + strings.Join(parts, " ") + " "
+}
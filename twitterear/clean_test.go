@@ -0,0 +1,158 @@
+package twitterear
+
+import (
+	"testing"
+
+	"github.com/dghubble/go-twitter/twitter"
+)
+
+func TestCleanTweetWithPolicy(t *testing.T) {
+	// The url spans runes 5 to 21, the mention 26 to 30 and the hashtag
+	// 31 to 38
+	text := "Look https://t.co/abc now @bob #GoLang rocks"
+	tweet := twitter.Tweet{
+		FullText: text,
+		Lang:     "en",
+		Entities: &twitter.Entities{
+			Urls: []twitter.URLEntity{{
+				URL:        "https://t.co/abc",
+				DisplayURL: "example.com/post",
+				Indices:    twitter.Indices{5, 21},
+			}},
+			UserMentions: []twitter.MentionEntity{{
+				ScreenName: "bob",
+				Indices:    twitter.Indices{26, 30},
+			}},
+			Hashtags: []twitter.HashtagEntity{{
+				Text:    "GoLang",
+				Indices: twitter.Indices{31, 38},
+			}},
+		},
+	}
+
+	tests := []struct {
+		policy string
+		want   string
+	}{
+		{policy: "", want: "look now rocks"},
+		{policy: "urls=keep", want: "look example.com/post now rocks"},
+		{policy: "urls=replace,mentions=replace,hashtags=replace", want: "look <URL> now <USER> <HASHTAG> rocks"},
+		{policy: "mentions=keep,hashtags=keep", want: "look now bob golang rocks"},
+	}
+
+	for _, test := range tests {
+		policy, err := ParseCleanPolicy(test.policy)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		clean, _ := CleanTweetWithPolicy(tweet, policy)
+		if clean != test.want {
+			t.Errorf("policy %q: clean text = %q, want %q", test.policy, clean, test.want)
+		}
+	}
+}
+
+func TestCleanTweetWithEscapedText(t *testing.T) {
+	// Indices count "&" as one rune while the text carries "&amp;"
+	tweet := twitter.Tweet{
+		FullText: "Tom &amp; Jerry #Cartoon @bob https://t.co/x &lt;3",
+		Lang:     "en",
+		Entities: &twitter.Entities{
+			Hashtags: []twitter.HashtagEntity{{
+				Text:    "Cartoon",
+				Indices: twitter.Indices{12, 20},
+			}},
+			UserMentions: []twitter.MentionEntity{{
+				ScreenName: "bob",
+				Indices:    twitter.Indices{21, 25},
+			}},
+			Urls: []twitter.URLEntity{{
+				URL:        "https://t.co/x",
+				DisplayURL: "example.com/x",
+				Indices:    twitter.Indices{26, 40},
+			}},
+		},
+	}
+
+	tests := []struct {
+		policy string
+		want   string
+		// stored is the clean text of the stored full text, which has no
+		// displayed urls
+		stored string
+	}{
+		{policy: "", want: "tom jerry 3", stored: "tom jerry 3"},
+		{policy: "urls=replace,mentions=replace,hashtags=replace", want: "tom jerry <HASHTAG> <USER> <URL> 3", stored: "tom jerry <HASHTAG> <USER> <URL> 3"},
+		{policy: "urls=keep,mentions=keep,hashtags=keep", want: "tom jerry cartoon bob example.com/x 3", stored: "tom jerry cartoon bob https://t.co/x 3"},
+	}
+
+	for _, test := range tests {
+		policy, err := ParseCleanPolicy(test.policy)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		clean, _ := CleanTweetWithPolicy(tweet, policy)
+		if clean != test.want {
+			t.Errorf("policy %q: clean text = %q, want %q", test.policy, clean, test.want)
+		}
+
+		// Stored full texts are escaped the same way
+		clean, _ = CleanTextWithPolicy(tweet.FullText, tweet.Lang, policy)
+		if clean != test.stored {
+			t.Errorf("policy %q: clean stored text = %q, want %q", test.policy, clean, test.stored)
+		}
+	}
+}
+
+func TestCleanSegmentKeepsUrlsFollowedByText(t *testing.T) {
+	// Urls missing from the entities are found in the text, followed by a
+	// space they used to lose their kept or replaced token
+	tests := []struct {
+		policy string
+		want   string
+	}{
+		{policy: "urls=keep", want: "see https://example.com/a now"},
+		{policy: "urls=replace", want: "see <URL> now"},
+		{policy: "urls=drop", want: "see now"},
+	}
+
+	tweet := twitter.Tweet{FullText: "See https://example.com/a now", Lang: "en"}
+	for _, test := range tests {
+		policy, err := ParseCleanPolicy(test.policy)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		clean, _ := CleanTweetWithPolicy(tweet, policy)
+		if clean != test.want {
+			t.Errorf("policy %q: clean text = %q, want %q", test.policy, clean, test.want)
+		}
+	}
+}
+
+func TestCleanTextWithPolicy(t *testing.T) {
+	text := "Look https://t.co/abc now @bob #GoLang rocks, me@home"
+
+	tests := []struct {
+		policy string
+		want   string
+	}{
+		{policy: "", want: "look now rocks me home"},
+		{policy: "urls=replace,mentions=replace,hashtags=replace", want: "look <URL> now <USER> <HASHTAG> rocks me home"},
+		{policy: "mentions=keep,hashtags=keep", want: "look now bob golang rocks me home"},
+	}
+
+	for _, test := range tests {
+		policy, err := ParseCleanPolicy(test.policy)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		clean, _ := CleanTextWithPolicy(text, "en", policy)
+		if clean != test.want {
+			t.Errorf("policy %q: clean text = %q, want %q", test.policy, clean, test.want)
+		}
+	}
+}
@@ -0,0 +1,279 @@
+package twitterear
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/dghubble/go-twitter/twitter"
+	"github.com/thebigear/database"
+	"github.com/thebigear/features"
+	"github.com/thebigear/inclusion"
+	"github.com/thebigear/models"
+	"github.com/thebigear/sentiment"
+	"github.com/thebigear/utils"
+)
+
+// Collector cleans, enriches and stores tweets as expressions
+type Collector struct {
+	Source TweetSource
+	// Labeler labels attached photos and video thumbnails, nil leaves them
+	// unlabeled
+	Labeler    ImageLabeler
+	Downloader *Downloader
+	// Cache keeps media and labels on disk, nil disables it
+	Cache *MediaCache
+	// CleanPolicy decides what becomes of entities in clean text
+	CleanPolicy CleanPolicy
+	// Rules decide which tweets are stored
+	Rules *inclusion.RuleSet
+	// Stats counts the decisions of Rules, reset it between runs
+	Stats *models.InclusionStats
+	// HistorySize is how many earlier tweets of the author are summarized,
+	// 0 skips the author timeline
+	HistorySize int
+	// MaturityAge is the age from which interaction counts are mature
+	MaturityAge time.Duration
+	// Campaign tags collected expressions, empty for ad hoc collection
+	Campaign string
+}
+
+// NewCollector creates a Collector reading author timelines from source and
+// labeling photos with labeler. Media are cached in MEDIA_CACHE_DIR unless
+// it is set to "none", text is cleaned with the CLEAN_POLICY policies and
+// AUTHOR_HISTORY_SIZE earlier tweets of authors are summarized. Counts of
+// tweets older than MATURITY_AGE are tagged mature. Tweets are included by
+// the rules at INCLUSION_RULES, DefaultRules when it is not set.
+func NewCollector(source TweetSource, labeler ImageLabeler) *Collector {
+	collector := &Collector{
+		Source:      source,
+		Labeler:     labeler,
+		Downloader:  NewDownloader(),
+		CleanPolicy: CleanPolicyFromEnv(),
+		Rules:       inclusion.DefaultRules,
+		Stats:       models.NewInclusionStats(),
+		HistorySize: utils.GetEnvIntOrDefault("AUTHOR_HISTORY_SIZE", DefaultHistorySize),
+		MaturityAge: utils.GetEnvDurationOrDefault("MATURITY_AGE", DefaultMaturityAge),
+	}
+
+	if path := utils.GetEnvOrDefault("INCLUSION_RULES", ""); path != "" {
+		rules, err := inclusion.LoadRules(path)
+		if err != nil {
+			fmt.Println("Error loading INCLUSION_RULES ", err)
+		} else {
+			collector.Rules = rules
+		}
+	}
+
+	if dir := utils.GetEnvOrDefault("MEDIA_CACHE_DIR", "media_cache"); dir != "none" {
+		cache, err := NewMediaCache(dir)
+		if err != nil {
+			fmt.Println("Error opening media cache ", err)
+		} else {
+			collector.Cache = cache
+		}
+	}
+
+	return collector
+}
+
+// Collect stores tweet as an expression unless it is a duplicate or the
+// inclusion rules reject it, counting the decision in Stats
+func (c *Collector) Collect(tweet twitter.Tweet) (*models.Expression, error) {
+
+	query := database.Query{}
+	query["post_id"] = tweet.ID
+
+	if duplicate, _ := models.GetExpression(query); duplicate != nil {
+		c.Stats.Duplicate()
+		return nil, nil
+	}
+
+	expression := NewExpression(tweet, c.CleanPolicy)
+	expression.Campaign = c.Campaign
+	interactions := TweetInteractions(tweet, time.Now())
+	totalInteraction := interactions.Total()
+	// Counts of unknown age can not be told mature
+	age, known := interactions.Age()
+	mature := known && age >= c.MaturityAge
+	expression.TotalInteraction = &totalInteraction
+	expression.Interactions = interactions
+	expression.Mature = &mature
+
+	var media []models.Media
+	for _, entity := range TweetMedia(tweet) {
+		media = append(media, NewMedia(entity))
+	}
+	expression.Media = media
+
+	// Decide before the enrichments that cost API calls
+	decision := c.Rules.Decide(expression)
+	if !decision.Accepted {
+		c.Stats.Reject(decision.Reason)
+		return nil, nil
+	}
+	c.Stats.Accept(decision.Flags)
+	expression.Flags = decision.Flags
+
+	fmt.Print("\nCLEAN TEXT: ", expression.CleanText)
+
+	if c.HistorySize > 0 {
+		history, lastTen, err := GetAuthorHistory(c.Source, tweet, c.HistorySize)
+		if err != nil {
+			// Store the tweet anyway, leaving its author history unknown
+			fmt.Println("Error getting user timeline ", tweet.User.ID, err)
+		} else {
+			expression.AuthorHistory = history
+			expression.LastTenInteraction = &lastTen
+		}
+	}
+
+	if c.Labeler != nil {
+		for i := range media {
+			c.labelMedia(&media[i])
+		}
+	}
+	expression.SetMedia(media)
+	expression.Features = features.Extract(expression)
+
+	return expression.Create()
+}
+
+// SetMinAge tells the collector its tweets are searched at least minAge
+// old. The built in rules judge interaction counts, below MaturityAge they
+// are swapped for inclusion.FreshRules.
+func (c *Collector) SetMinAge(minAge time.Duration) {
+	if c.Rules == inclusion.DefaultRules && minAge < c.MaturityAge {
+		c.Rules = inclusion.FreshRules
+	}
+}
+
+// SaveRun prints and stores run with the rules and stats of the collector
+// and its campaign
+func (c *Collector) SaveRun(run *models.CollectionRun) {
+	stats := c.Stats
+	fmt.Printf("Seen %d, accepted %d (%d flagged), rejected %d, duplicates %d\n",
+		stats.Seen, stats.Accepted, stats.Flagged, stats.Rejected, stats.Duplicates)
+	for reason, rejected := range stats.Reasons {
+		fmt.Printf("  rejected by %s: %d\n", reason, rejected)
+	}
+	for flag, flagged := range stats.Flags {
+		fmt.Printf("  flagged by %s: %d\n", flag, flagged)
+	}
+
+	run.Campaign = c.Campaign
+	run.Rules = c.Rules.Source
+	run.InclusionStats = *stats
+	if _, err := run.Create(); err != nil {
+		fmt.Println("Error saving collection run ", err)
+	}
+}
+
+// NewExpression builds the expression of tweet from its text, author and
+// posting time, before any timeline, interaction or media enrichment
+func NewExpression(tweet twitter.Tweet, policy CleanPolicy) *models.Expression {
+	cleanText, emojis := CleanTweetWithPolicy(tweet, policy)
+	isVerified := tweet.User.Verified
+	hasAtatchments := HasAttachment(tweet)
+	followerCount := tweet.User.FollowersCount
+	followingCount := tweet.User.FriendsCount
+	postsCount := tweet.User.StatusesCount
+
+	expression := &models.Expression{}
+	expression.PostID = tweet.ID
+	expression.Owner = tweet.User.IDStr
+	expression.FullText = tweet.FullText
+	expression.CleanText = cleanText
+	expression.Emojis = emojis
+	expression.Lang = tweet.Lang
+	expression.Analysis = sentiment.AnalyzeLang(tweet.FullText, tweet.Lang)
+	expression.IsVerified = &isVerified
+	expression.HasAttachment = &hasAtatchments
+	expression.Followers = &followerCount
+	expression.Following = &followingCount
+	expression.PostCount = &postsCount
+
+	if postedAt, err := tweet.CreatedAtTime(); err == nil {
+		expression.PostedAt = postedAt
+	}
+	if ownerCreatedAt, err := time.Parse(time.RubyDate, tweet.User.CreatedAt); err == nil {
+		expression.OwnerCreatedAt = ownerCreatedAt
+	}
+
+	return expression
+}
+
+// labelMedia labels the photo or thumbnail of media. Failures are recorded
+// on the media, which is stored without labels.
+func (c *Collector) labelMedia(media *models.Media) {
+	imagebytes, hash, err := c.fetchMedia(media.URL)
+	if err != nil {
+		fmt.Println("Error downloading media ", media.URL, err)
+		media.Error = err.Error()
+		return
+	}
+	media.Hash = hash
+
+	labels, err := c.detectLabels(imagebytes, hash)
+	if err == ErrNoLabels {
+		return
+	}
+	if err != nil {
+		media.Error = err.Error()
+		return
+	}
+
+	media.Labels = labels
+}
+
+// fetchMedia returns the media at url and its hash, from the cache when
+// it has been downloaded before
+func (c *Collector) fetchMedia(url string) ([]byte, string, error) {
+	if c.Cache != nil {
+		if data, hash, ok := c.Cache.Get(url); ok {
+			return data, hash, nil
+		}
+	}
+
+	data, _, err := c.Downloader.DownloadImage(url)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if c.Cache == nil {
+		return data, MediaHash(data), nil
+	}
+
+	hash, err := c.Cache.Put(url, data)
+	if err != nil {
+		fmt.Println("Error caching media ", url, err)
+		hash = MediaHash(data)
+	}
+	return data, hash, nil
+}
+
+// detectLabels labels media with given hash, reusing the labels cached for
+// the same image. Images without labels are cached too, so they are not
+// paid for again.
+func (c *Collector) detectLabels(data []byte, hash string) (models.Labels, error) {
+	if c.Cache != nil {
+		if labels, ok := c.Cache.Labels(hash, c.Labeler.Name()); ok {
+			if len(labels) == 0 {
+				return nil, ErrNoLabels
+			}
+			return labels, nil
+		}
+	}
+
+	labels, err := c.Labeler.DetectLabels(data)
+	if err != nil && err != ErrNoLabels {
+		return nil, err
+	}
+
+	if c.Cache != nil {
+		if err := c.Cache.PutLabels(hash, c.Labeler.Name(), labels); err != nil {
+			fmt.Println("Error caching labels ", hash, err)
+		}
+	}
+
+	return labels, err
+}
@@ -0,0 +1,136 @@
+package twitterear
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/thebigear/database"
+	"github.com/thebigear/inclusion"
+	"github.com/thebigear/models"
+)
+
+// connectTestDatabase connects to the throwaway database at MONGO_TEST_URL
+// and returns a function dropping it, tests storing expressions are
+// skipped without one
+func connectTestDatabase(t *testing.T) func() {
+	url := os.Getenv("MONGO_TEST_URL")
+	if url == "" {
+		t.Skip("MONGO_TEST_URL is not set")
+	}
+
+	os.Setenv("MONGO_URL", url)
+	database.Connect()
+	database.Mongo.DropDatabase()
+
+	return func() {
+		database.Mongo.DropDatabase()
+	}
+}
+
+func TestCollectReplayedSearch(t *testing.T) {
+	defer connectTestDatabase(t)()
+
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+
+	popular := testTweet(500, "Loving the #sunshine today", 12, 3, 72*time.Hour)
+	quiet := testTweet(400, "nobody saw this", 0, 0, 72*time.Hour)
+	path := writeRecordings(t, dir,
+		testSearch(false, popular, quiet),
+		testTimeline(
+			// Posted after the collected tweet, it must not leak in
+			testTweet(600, "later", 100, 0, time.Hour),
+			testTweet(450, "earlier", 4, 0, 96*time.Hour),
+			testTweet(350, "earliest", 8, 1, 120*time.Hour),
+		),
+	)
+
+	source, err := NewReplaySource(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	collector := &Collector{
+		Source:      source,
+		CleanPolicy: DefaultCleanPolicy,
+		Rules:       inclusion.DefaultRules,
+		Stats:       models.NewInclusionStats(),
+		HistorySize: DefaultHistorySize,
+		MaturityAge: DefaultMaturityAge,
+	}
+
+	key := "sunshine"
+	count := 100
+	total := 100
+	checkpoint := &models.SearchCheckpoint{Key: key}
+	tweets := GetTweetsFromSearchApi(source, &key, &count, &total, ResultTypeMixed, DefaultSearchWindow, checkpoint)
+	if len(tweets) != 2 {
+		t.Fatalf("searched %d tweets, want 2", len(tweets))
+	}
+
+	for _, tweet := range tweets {
+		if _, err := collector.Collect(tweet); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Collecting again finds the stored tweet
+	if expression, err := collector.Collect(popular); err != nil || expression != nil {
+		t.Fatalf("collected a duplicate: %v %v", expression, err)
+	}
+
+	stats := collector.Stats
+	if stats.Seen != 3 || stats.Accepted != 1 || stats.Rejected != 1 || stats.Duplicates != 1 {
+		t.Errorf("stats = %+v, want 3 seen, 1 accepted, 1 rejected and 1 duplicate", stats)
+	}
+	if stats.Reasons["low_interaction"] != 1 {
+		t.Errorf("rejection reasons = %v, want low_interaction", stats.Reasons)
+	}
+
+	expression, err := models.GetExpression(database.Query{"post_id": popular.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expression.TotalInteraction == nil || *expression.TotalInteraction != 15 {
+		t.Errorf("total interaction = %v, want 15", expression.TotalInteraction)
+	}
+	if expression.Mature == nil || !*expression.Mature {
+		t.Errorf("a tweet of 72h is not mature")
+	}
+	if expression.AuthorHistory == nil || expression.AuthorHistory.Count != 2 {
+		t.Fatalf("author history = %+v, want the 2 earlier tweets", expression.AuthorHistory)
+	}
+	if expression.LastTenInteraction == nil || *expression.LastTenInteraction != 13 {
+		t.Errorf("last ten interaction = %v, want 13", expression.LastTenInteraction)
+	}
+	if expression.Features == nil || expression.Analysis == nil {
+		t.Errorf("expression was stored without features or analysis")
+	}
+
+	if _, err := models.GetExpression(database.Query{"post_id": quiet.ID}); err == nil {
+		t.Errorf("rejected tweet was stored")
+	}
+}
+
+func TestSetMinAge(t *testing.T) {
+	custom := &inclusion.RuleSet{Source: "custom.json"}
+
+	tests := []struct {
+		rules  *inclusion.RuleSet
+		minAge time.Duration
+		want   *inclusion.RuleSet
+	}{
+		{rules: inclusion.DefaultRules, minAge: 0, want: inclusion.FreshRules},
+		{rules: inclusion.DefaultRules, minAge: time.Hour, want: inclusion.FreshRules},
+		{rules: inclusion.DefaultRules, minAge: DefaultMaturityAge, want: inclusion.DefaultRules},
+		{rules: custom, minAge: 0, want: custom},
+	}
+
+	for _, test := range tests {
+		collector := &Collector{Rules: test.rules, MaturityAge: DefaultMaturityAge}
+		collector.SetMinAge(test.minAge)
+		if collector.Rules != test.want {
+			t.Errorf("%s rules at min age %s became %s, want %s", test.rules.Source, test.minAge, collector.Rules.Source, test.want.Source)
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package twitterear
+
+import (
+	"time"
+
+	"github.com/dghubble/go-twitter/twitter"
+	"github.com/thebigear/features"
+	"github.com/thebigear/models"
+)
+
+// DraftExpression builds the expression draft would be collected as,
+// cleaned with policy and with its features extracted. Hashtags and
+// mentions are located in the text, as a draft has no entities, and the
+// text is escaped like Twitter escapes tweets.
+func DraftExpression(draft *models.Draft, policy CleanPolicy) *models.Expression {
+	postAt := draft.PostAt
+	if postAt.IsZero() {
+		postAt = time.Now()
+	}
+
+	tweet := twitter.Tweet{
+		FullText:  EscapeTweetText(draft.Text),
+		Lang:      draft.Lang,
+		Entities:  TextEntities(draft.Text),
+		CreatedAt: postAt.UTC().Format(time.RubyDate),
+		User: &twitter.User{
+			FollowersCount: draft.Followers,
+			FriendsCount:   draft.Following,
+			StatusesCount:  draft.PostCount,
+			Verified:       draft.IsVerified,
+		},
+	}
+	if !draft.AccountCreatedAt.IsZero() {
+		tweet.User.CreatedAt = draft.AccountCreatedAt.UTC().Format(time.RubyDate)
+	}
+
+	expression := NewExpression(tweet, policy)
+	expression.LastTenInteraction = draft.LastTenInteraction
+	expression.AuthorHistory = draft.AuthorHistory
+
+	mediaCount := draft.MediaCount
+	if mediaCount == 0 && len(draft.Labels) > 0 {
+		mediaCount = 1
+	}
+
+	var labels models.Labels
+	for _, name := range draft.Labels {
+		labels = append(labels, models.Label{Name: name, Confidence: 100})
+	}
+
+	media := make([]models.Media, mediaCount)
+	for i := range media {
+		media[i].Type = models.MediaTypePhoto
+	}
+	if mediaCount > 0 {
+		media[0].Labels = labels
+	}
+	expression.SetMedia(media)
+
+	hasAttachment := mediaCount > 0
+	expression.HasAttachment = &hasAttachment
+	expression.Features = features.Extract(expression)
+
+	return expression
+}
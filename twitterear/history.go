@@ -0,0 +1,104 @@
+package twitterear
+
+import (
+	"github.com/dghubble/go-twitter/twitter"
+	"github.com/thebigear/models"
+)
+
+// DefaultHistorySize is how many earlier tweets of an author are summarized
+const DefaultHistorySize = 10
+
+// lastTenSize is how many earlier tweets last_ten_interaction sums,
+// whatever the history size
+const lastTenSize = 10
+
+// Timeline pages are capped by Twitter, replies and retweets are filtered
+// out after a page is picked so a page may hold fewer own tweets
+const (
+	maxTimelineCount = 200
+	maxTimelinePages = 5
+)
+
+// GetAuthorHistory summarizes the interactions of the last size own tweets
+// the author of tweet posted strictly before it, along with the sum of the
+// last ten of them. Ten tweets are read even for smaller sizes, so the sum
+// means the same whatever the size.
+func GetAuthorHistory(source TweetSource, tweet twitter.Tweet, size int) (*models.AuthorHistory, int, error) {
+	count := size
+	if count < lastTenSize {
+		count = lastTenSize
+	}
+
+	earlier, err := GetEarlierUserTweets(source, tweet, count)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var interactions []int
+	lastTen := 0
+	for i, userTweet := range earlier {
+		interaction := userTweet.FavoriteCount + userTweet.RetweetCount
+		if i < size {
+			interactions = append(interactions, interaction)
+		}
+		if i < lastTenSize {
+			lastTen += interaction
+		}
+	}
+
+	return models.NewAuthorHistory(size, interactions), lastTen, nil
+}
+
+// GetEarlierUserTweets returns up to count own tweets of the author of
+// tweet posted before it, newest first. Only tweets with smaller ids and
+// earlier creation times qualify, so nothing posted after the tweet, nor
+// the tweet itself, leaks in.
+func GetEarlierUserTweets(source TweetSource, tweet twitter.Tweet, count int) ([]twitter.Tweet, error) {
+	postedAt, err := tweet.CreatedAtTime()
+	if err != nil {
+		return nil, err
+	}
+
+	er := true
+	ir := false
+
+	params := &twitter.UserTimelineParams{
+		Count:           maxTimelineCount,
+		ExcludeReplies:  &er,
+		IncludeRetweets: &ir,
+		UserID:          tweet.User.ID,
+		MaxID:           tweet.ID - 1,
+	}
+
+	var earlier []twitter.Tweet
+	for page := 0; page < maxTimelinePages && len(earlier) < count; page++ {
+		timeline, err := source.UserTimeline(params)
+		if err != nil {
+			return nil, err
+		}
+
+		oldest := params.MaxID
+		for _, userTweet := range timeline {
+			if userTweet.ID < oldest {
+				oldest = userTweet.ID
+			}
+
+			createdAt, err := userTweet.CreatedAtTime()
+			if err != nil || userTweet.ID >= tweet.ID || !createdAt.Before(postedAt) {
+				continue
+			}
+			if len(earlier) < count {
+				earlier = append(earlier, userTweet)
+			}
+		}
+
+		// An empty page or one without older tweets is the end of the
+		// timeline
+		if len(timeline) == 0 || oldest >= params.MaxID {
+			break
+		}
+		params.MaxID = oldest - 1
+	}
+
+	return earlier, nil
+}
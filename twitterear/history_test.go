@@ -0,0 +1,50 @@
+package twitterear
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/dghubble/go-twitter/twitter"
+)
+
+func TestGetAuthorHistoryLastTen(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+
+	// Twelve earlier tweets, newest first, with 1 to 12 favorites
+	var earlier []twitter.Tweet
+	for i := 1; i <= 12; i++ {
+		earlier = append(earlier, testTweet(int64(1000-i), "earlier", i, 0, time.Duration(i+1)*time.Hour))
+	}
+	source, err := NewReplaySource(writeRecordings(t, dir, testTimeline(earlier...)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tweet := testTweet(1000, "collected", 0, 0, time.Hour)
+
+	tests := []struct {
+		size  int
+		count int
+		mean  float64
+	}{
+		{size: 3, count: 3, mean: 2},
+		{size: 10, count: 10, mean: 5.5},
+		{size: 12, count: 12, mean: 6.5},
+	}
+
+	for _, test := range tests {
+		history, lastTen, err := GetAuthorHistory(source, tweet, test.size)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if history.Size != test.size || history.Count != test.count || history.Mean != test.mean {
+			t.Errorf("size %d: history %+v, want %d tweets with mean %v", test.size, history, test.count, test.mean)
+		}
+		// The sum always covers the ten most recent tweets, 1 to 10
+		if lastTen != 55 {
+			t.Errorf("size %d: last ten interaction = %d, want 55", test.size, lastTen)
+		}
+	}
+}
@@ -0,0 +1,227 @@
+package twitterear
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/rekognition"
+	"github.com/thebigear/models"
+	"github.com/thebigear/utils"
+)
+
+// Labeler kinds selectable with IMAGE_LABELER
+const (
+	// LabelerAuto uses Rekognition when AWS credentials are available and
+	// the local labeler otherwise
+	LabelerAuto        = "auto"
+	LabelerRekognition = "rekognition"
+	LabelerLocal       = "local"
+	LabelerFake        = "fake"
+	LabelerNone        = "none"
+)
+
+// ErrNoLabels is returned when a labeler finds nothing on an image
+var ErrNoLabels = errors.New("No Match")
+
+// ImageLabeler detects labels of an image
+type ImageLabeler interface {
+	// Name identifies the labeler, outputs of different labelers are
+	// cached apart
+	Name() string
+	DetectLabels(image []byte) (models.Labels, error)
+}
+
+// NewImageLabeler creates the labeler of given kind, an empty kind is read
+// from IMAGE_LABELER. LabelerNone returns a nil labeler.
+func NewImageLabeler(kind string) (ImageLabeler, error) {
+	if kind == "" {
+		kind = utils.GetEnvOrDefault("IMAGE_LABELER", LabelerAuto)
+	}
+
+	switch kind {
+	case LabelerAuto:
+		labeler, err := NewRekognitionLabeler()
+		if err == nil {
+			_, err = labeler.session.Config.Credentials.Get()
+		}
+		if err != nil {
+			fmt.Println("Rekognition is not available, using local labeler: ", err)
+			return NewLocalLabeler(), nil
+		}
+		return labeler, nil
+	case LabelerRekognition:
+		labeler, err := NewRekognitionLabeler()
+		if err != nil {
+			return nil, err
+		}
+		return labeler, nil
+	case LabelerLocal:
+		return NewLocalLabeler(), nil
+	case LabelerFake:
+		return NewFakeLabeler(), nil
+	case LabelerNone:
+		return nil, nil
+	}
+
+	return nil, fmt.Errorf("unknown image labeler %q", kind)
+}
+
+// RekognitionLabeler labels images with Amazon Rekognition
+type RekognitionLabeler struct {
+	Client        *rekognition.Rekognition
+	MinConfidence float64
+
+	session *session.Session
+}
+
+// NewRekognitionLabeler creates a RekognitionLabeler from the default AWS session
+func NewRekognitionLabeler() (*RekognitionLabeler, error) {
+
+	sess, err := session.NewSession()
+	if err != nil {
+		fmt.Println("Error creating session ", err)
+		return nil, err
+	}
+
+	// Create a Rekognition client from just a session.
+	return &RekognitionLabeler{
+		Client:        rekognition.New(sess),
+		MinConfidence: 60,
+		session:       sess,
+	}, nil
+
+}
+
+// Name returns LabelerRekognition
+func (l *RekognitionLabeler) Name() string {
+	return LabelerRekognition
+}
+
+// DetectLabels detects labels of image with Rekognition
+func (l *RekognitionLabeler) DetectLabels(data []byte) (models.Labels, error) {
+
+	mc := l.MinConfidence
+	params := &rekognition.DetectLabelsInput{
+		Image: &rekognition.Image{ // Required
+			Bytes: data,
+		},
+		MinConfidence: &mc,
+	}
+
+	result, err := l.Client.DetectLabels(params)
+	if err != nil {
+		if aerr, ok := err.(awserr.Error); ok {
+			switch aerr.Code() {
+			case rekognition.ErrCodeInvalidS3ObjectException:
+				fmt.Println(rekognition.ErrCodeInvalidS3ObjectException, aerr.Error())
+			case rekognition.ErrCodeInvalidParameterException:
+				fmt.Println(rekognition.ErrCodeInvalidParameterException, aerr.Error())
+			case rekognition.ErrCodeImageTooLargeException:
+				fmt.Println(rekognition.ErrCodeImageTooLargeException, aerr.Error())
+			case rekognition.ErrCodeAccessDeniedException:
+				fmt.Println(rekognition.ErrCodeAccessDeniedException, aerr.Error())
+			case rekognition.ErrCodeInternalServerError:
+				fmt.Println(rekognition.ErrCodeInternalServerError, aerr.Error())
+			case rekognition.ErrCodeThrottlingException:
+				fmt.Println(rekognition.ErrCodeThrottlingException, aerr.Error())
+			case rekognition.ErrCodeProvisionedThroughputExceededException:
+				fmt.Println(rekognition.ErrCodeProvisionedThroughputExceededException, aerr.Error())
+			case rekognition.ErrCodeInvalidImageFormatException:
+				fmt.Println(rekognition.ErrCodeInvalidImageFormatException, aerr.Error())
+			default:
+				fmt.Println(aerr.Error())
+			}
+		} else {
+			// Print the error, cast err to awserr.Error to get the Code and
+			// Message from an error.
+			fmt.Println(err.Error())
+		}
+		return nil, err
+	}
+	var labels models.Labels
+
+	if len(result.Labels) > 0 {
+
+		for _, element := range result.Labels {
+
+			label := models.Label{
+				Name:       aws.StringValue(element.Name),
+				Confidence: aws.Float64Value(element.Confidence),
+			}
+			for _, parent := range element.Parents {
+				label.Parents = append(label.Parents, aws.StringValue(parent.Name))
+			}
+			for _, instance := range element.Instances {
+				labelInstance := models.LabelInstance{
+					Confidence: aws.Float64Value(instance.Confidence),
+				}
+				if box := instance.BoundingBox; box != nil {
+					labelInstance.Left = aws.Float64Value(box.Left)
+					labelInstance.Top = aws.Float64Value(box.Top)
+					labelInstance.Width = aws.Float64Value(box.Width)
+					labelInstance.Height = aws.Float64Value(box.Height)
+				}
+				label.Instances = append(label.Instances, labelInstance)
+			}
+
+			labels = append(labels, label)
+
+		}
+
+		return labels, nil
+
+	}
+
+	return nil, ErrNoLabels
+
+}
+
+// fakeLabels is the vocabulary FakeLabeler picks from
+var fakeLabels = []string{
+	"Person", "Text", "Animal", "Food", "Outdoors",
+	"Vehicle", "Sport", "Screenshot", "Nature", "Building",
+}
+
+// FakeLabeler derives labels from a hash of the image, so the same image
+// always gets the same labels without calling any service
+type FakeLabeler struct{}
+
+// NewFakeLabeler creates a FakeLabeler
+func NewFakeLabeler() *FakeLabeler {
+	return &FakeLabeler{}
+}
+
+// Name returns LabelerFake
+func (l *FakeLabeler) Name() string {
+	return LabelerFake
+}
+
+// DetectLabels picks one to three labels of image with confidences
+// between 50 and 100
+func (l *FakeLabeler) DetectLabels(data []byte) (models.Labels, error) {
+	if len(data) == 0 {
+		return nil, ErrNoLabels
+	}
+
+	sum := sha256.Sum256(data)
+	count := int(sum[0]%3) + 1
+
+	var labels models.Labels
+	seen := map[string]bool{}
+	for i := 1; len(labels) < count && i < len(sum)-1; i += 2 {
+		name := fakeLabels[int(sum[i])%len(fakeLabels)]
+		if !seen[name] {
+			seen[name] = true
+			labels = append(labels, models.Label{
+				Name:       name,
+				Confidence: 50 + float64(sum[i+1]%51),
+			})
+		}
+	}
+
+	return labels, nil
+}
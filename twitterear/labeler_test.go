@@ -0,0 +1,118 @@
+package twitterear
+
+import (
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestNewImageLabeler(t *testing.T) {
+	defer os.Setenv("IMAGE_LABELER", os.Getenv("IMAGE_LABELER"))
+
+	tests := []struct {
+		kind string
+		env  string
+		// names are the acceptable labelers, none for a nil labeler
+		names []string
+		err   bool
+	}{
+		{kind: LabelerLocal, names: []string{LabelerLocal}},
+		{kind: LabelerFake, names: []string{LabelerFake}},
+		{kind: LabelerRekognition, names: []string{LabelerRekognition}},
+		{kind: LabelerNone},
+		{kind: "", env: LabelerFake, names: []string{LabelerFake}},
+		{kind: "", env: LabelerNone},
+		{kind: LabelerLocal, env: LabelerFake, names: []string{LabelerLocal}},
+		// Rekognition when credentials are found, local otherwise
+		{kind: LabelerAuto, names: []string{LabelerRekognition, LabelerLocal}},
+		{kind: "clip", err: true},
+		{kind: "", env: "clip", err: true},
+	}
+
+	for _, test := range tests {
+		os.Setenv("IMAGE_LABELER", test.env)
+
+		labeler, err := NewImageLabeler(test.kind)
+		if test.err {
+			if err == nil {
+				t.Errorf("NewImageLabeler(%q) with IMAGE_LABELER=%q succeeded, want an error", test.kind, test.env)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("NewImageLabeler(%q) with IMAGE_LABELER=%q: %v", test.kind, test.env, err)
+			continue
+		}
+
+		if len(test.names) == 0 {
+			if labeler != nil {
+				t.Errorf("NewImageLabeler(%q) with IMAGE_LABELER=%q = %s, want none", test.kind, test.env, labeler.Name())
+			}
+			continue
+		}
+		if labeler == nil {
+			t.Errorf("NewImageLabeler(%q) with IMAGE_LABELER=%q = none, want %v", test.kind, test.env, test.names)
+			continue
+		}
+
+		found := false
+		for _, name := range test.names {
+			found = found || labeler.Name() == name
+		}
+		if !found {
+			t.Errorf("NewImageLabeler(%q) with IMAGE_LABELER=%q = %s, want %v", test.kind, test.env, labeler.Name(), test.names)
+		}
+	}
+}
+
+func TestFakeLabeler(t *testing.T) {
+	labeler := NewFakeLabeler()
+
+	tests := []struct {
+		name  string
+		image []byte
+		err   error
+	}{
+		{name: "empty", image: nil, err: ErrNoLabels},
+		{name: "single byte", image: []byte{1}},
+		{name: "text", image: []byte("not really an image")},
+		{name: "binary", image: []byte{0xff, 0xd8, 0xff, 0xe0, 0x00, 0x10}},
+	}
+
+	seen := map[string]bool{}
+	for _, test := range tests {
+		labels, err := labeler.DetectLabels(test.image)
+		if err != test.err {
+			t.Errorf("%s: err = %v, want %v", test.name, err, test.err)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+
+		if len(labels) < 1 || len(labels) > 3 {
+			t.Errorf("%s: %d labels, want 1 to 3", test.name, len(labels))
+		}
+		names := map[string]bool{}
+		for _, label := range labels {
+			if names[label.Name] {
+				t.Errorf("%s: label %s repeated", test.name, label.Name)
+			}
+			names[label.Name] = true
+			if label.Confidence < 50 || label.Confidence > 100 {
+				t.Errorf("%s: confidence %v of %s out of 50..100", test.name, label.Confidence, label.Name)
+			}
+		}
+
+		// The same image always gets the same labels
+		again, _ := labeler.DetectLabels(test.image)
+		if !reflect.DeepEqual(labels, again) {
+			t.Errorf("%s: labels %v then %v", test.name, labels, again)
+		}
+		seen[labels.String()] = true
+	}
+
+	if len(seen) < 2 {
+		t.Errorf("different images all got the labels %v", seen)
+	}
+}
@@ -0,0 +1,159 @@
+package twitterear
+
+import (
+	"bytes"
+	"image"
+	// Register the formats tweets carry
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+
+	"github.com/thebigear/models"
+)
+
+// localLabelerSamples is about how many pixels LocalLabeler looks at
+const localLabelerSamples = 128 * 128
+
+// LocalLabeler labels images from their pixels alone, with orientation,
+// brightness, saturation and dominant color. It needs no external service.
+type LocalLabeler struct{}
+
+// NewLocalLabeler creates a LocalLabeler
+func NewLocalLabeler() *LocalLabeler {
+	return &LocalLabeler{}
+}
+
+// Name returns LabelerLocal
+func (l *LocalLabeler) Name() string {
+	return LabelerLocal
+}
+
+// DetectLabels decodes image and describes it. Confidences grow with how
+// far the image is past the threshold of a label.
+func (l *LocalLabeler) DetectLabels(data []byte) (models.Labels, error) {
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	if width == 0 || height == 0 {
+		return nil, ErrNoLabels
+	}
+
+	var labels models.Labels
+
+	switch ratio := float64(width) / float64(height); {
+	case ratio > 1.1:
+		labels = append(labels, localLabel("Landscape", "Orientation", 100))
+	case ratio < 0.9:
+		labels = append(labels, localLabel("Portrait", "Orientation", 100))
+	default:
+		labels = append(labels, localLabel("Square", "Orientation", 100))
+	}
+
+	step := 1
+	for (width/step)*(height/step) > localLabelerSamples {
+		step++
+	}
+
+	var samples, saturated int
+	var lightness, saturation float64
+	hues := make([]int, len(hueNames))
+
+	for y := bounds.Min.Y; y < bounds.Max.Y; y += step {
+		for x := bounds.Min.X; x < bounds.Max.X; x += step {
+			r, g, b, _ := img.At(x, y).RGBA()
+			h, s, v := toHSV(float64(r)/0xffff, float64(g)/0xffff, float64(b)/0xffff)
+
+			samples++
+			lightness += v
+			saturation += s
+			if s > 0.3 && v > 0.2 {
+				saturated++
+				hues[hueIndex(h)]++
+			}
+		}
+	}
+
+	lightness /= float64(samples)
+	saturation /= float64(samples)
+
+	if lightness < 0.25 {
+		labels = append(labels, localLabel("Dark", "Lighting", 50+(0.25-lightness)*200))
+	} else if lightness > 0.75 {
+		labels = append(labels, localLabel("Bright", "Lighting", 50+(lightness-0.75)*200))
+	}
+
+	if saturation < 0.1 {
+		labels = append(labels, localLabel("Monochrome", "Color", 50+(0.1-saturation)*500))
+	} else if saturation > 0.5 {
+		labels = append(labels, localLabel("Colorful", "Color", 50+(saturation-0.5)*100))
+	}
+
+	// Only name a color when a fair share of the image carries one
+	if saturated*5 > samples {
+		dominant := 0
+		for index, count := range hues {
+			if count > hues[dominant] {
+				dominant = index
+			}
+		}
+		share := float64(hues[dominant]) / float64(saturated)
+		labels = append(labels, localLabel(hueNames[dominant], "Color", share*100))
+	}
+
+	return labels, nil
+}
+
+func localLabel(name string, parent string, confidence float64) models.Label {
+	return models.Label{
+		Name:       name,
+		Confidence: confidence,
+		Parents:    []string{parent},
+	}
+}
+
+// hueNames names hue ranges, starting at red in 45 degree steps
+var hueNames = []string{"Red", "Orange", "Yellow", "Green", "Cyan", "Blue", "Purple", "Pink"}
+
+// hueIndex maps a hue in degrees to an index of hueNames
+func hueIndex(hue float64) int {
+	return int(hue+22.5) / 45 % len(hueNames)
+}
+
+// toHSV converts an RGB color with components in [0, 1] to hue in degrees,
+// saturation and value
+func toHSV(r, g, b float64) (float64, float64, float64) {
+	max, min := r, r
+	for _, c := range []float64{g, b} {
+		if c > max {
+			max = c
+		}
+		if c < min {
+			min = c
+		}
+	}
+
+	delta := max - min
+	if max == 0 || delta == 0 {
+		return 0, 0, max
+	}
+
+	var hue float64
+	switch max {
+	case r:
+		hue = (g - b) / delta
+	case g:
+		hue = 2 + (b-r)/delta
+	default:
+		hue = 4 + (r-g)/delta
+	}
+	hue *= 60
+	if hue < 0 {
+		hue += 360
+	}
+
+	return hue, delta / max, max
+}
@@ -0,0 +1,128 @@
+package twitterear
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/thebigear/utils"
+)
+
+// MaxInlineImageBytes is the largest image Rekognition accepts inline
+const MaxInlineImageBytes = 5 * 1024 * 1024
+
+var (
+	// ErrMediaTooLarge is returned for media above the downloader size limit
+	ErrMediaTooLarge = errors.New("media too large")
+
+	// ErrNotImage is returned when downloaded bytes are not an image
+	ErrNotImage = errors.New("media is not an image")
+)
+
+// Downloader fetches attached media with a timeout, a size limit and
+// retries with exponential backoff
+type Downloader struct {
+	Client   *http.Client
+	MaxBytes int64
+	Retries  int
+	Backoff  time.Duration
+}
+
+// NewDownloader creates a Downloader configured by MEDIA_TIMEOUT,
+// MEDIA_MAX_BYTES and MEDIA_RETRIES
+func NewDownloader() *Downloader {
+	return &Downloader{
+		Client: &http.Client{
+			Timeout: utils.GetEnvDurationOrDefault("MEDIA_TIMEOUT", 10*time.Second),
+		},
+		MaxBytes: int64(utils.GetEnvIntOrDefault("MEDIA_MAX_BYTES", MaxInlineImageBytes)),
+		Retries:  utils.GetEnvIntOrDefault("MEDIA_RETRIES", 3),
+		Backoff:  500 * time.Millisecond,
+	}
+}
+
+// permanentError wraps download errors retrying can not fix
+type permanentError struct {
+	err error
+}
+
+func (e permanentError) Error() string {
+	return e.err.Error()
+}
+
+// DownloadImage fetches the image at url, returning its bytes and sniffed
+// content type
+func (d *Downloader) DownloadImage(url string) ([]byte, string, error) {
+	wait := d.Backoff
+
+	for attempt := 0; ; attempt++ {
+		data, contentType, err := d.download(url)
+		if err == nil {
+			return data, contentType, nil
+		}
+
+		if permanent, ok := err.(permanentError); ok {
+			return nil, "", permanent.err
+		}
+		if attempt >= d.Retries {
+			return nil, "", err
+		}
+
+		fmt.Println("Retrying download of", url, "in", wait, err)
+		time.Sleep(wait)
+		wait *= 2
+	}
+}
+
+// download makes a single attempt to fetch the image at url
+func (d *Downloader) download(url string) ([]byte, string, error) {
+	res, err := d.Client.Get(url)
+	if err != nil {
+		return nil, "", err
+	}
+
+	// You have to manually close the body, check docs
+	// This is required if you want to use things like
+	// Keep-Alive and other HTTP sorcery.
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		err := fmt.Errorf("media download failed with status %d", res.StatusCode)
+		// Server errors and throttling may go away, anything else won't
+		if res.StatusCode >= 500 || res.StatusCode == http.StatusTooManyRequests {
+			return nil, "", err
+		}
+		return nil, "", permanentError{err}
+	}
+
+	if d.MaxBytes > 0 && res.ContentLength > d.MaxBytes {
+		return nil, "", permanentError{ErrMediaTooLarge}
+	}
+
+	// Read one byte past the limit to tell a full image from a cut one
+	var body io.Reader = res.Body
+	if d.MaxBytes > 0 {
+		body = io.LimitReader(res.Body, d.MaxBytes+1)
+	}
+
+	data, err := ioutil.ReadAll(body)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if d.MaxBytes > 0 && int64(len(data)) > d.MaxBytes {
+		return nil, "", permanentError{ErrMediaTooLarge}
+	}
+
+	// Trust the bytes rather than the Content-Type header
+	contentType := http.DetectContentType(data)
+	if !strings.HasPrefix(contentType, "image/") {
+		return nil, "", permanentError{ErrNotImage}
+	}
+
+	return data, contentType, nil
+}
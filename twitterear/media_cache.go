@@ -0,0 +1,165 @@
+package twitterear
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/thebigear/models"
+)
+
+// MediaCache keeps downloaded media and their labels on disk. Media are
+// addressed by the SHA-256 of their bytes and media urls point at those
+// hashes, so neither a known url nor a known image is fetched or labeled
+// twice.
+//
+// Layout under Dir:
+//
+//	objects/<hh>/<hash>             media bytes
+//	labels/<labeler>/<hh>/<hash>    labeler output as JSON
+//	urls/<hh>/<sha256 of url>       hash of the media at url
+type MediaCache struct {
+	Dir string
+}
+
+// NewMediaCache creates a MediaCache rooted at dir
+func NewMediaCache(dir string) (*MediaCache, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+
+	return &MediaCache{
+		Dir: dir,
+	}, nil
+}
+
+// MediaHash returns the hex SHA-256 of data
+func MediaHash(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// isMediaHash reports whether hash looks like a MediaHash
+func isMediaHash(hash string) bool {
+	if len(hash) != sha256.Size*2 {
+		return false
+	}
+	_, err := hex.DecodeString(hash)
+	return err == nil
+}
+
+// Get returns the cached media of url and its hash. Entries left empty or
+// truncated by an interrupted write are misses.
+func (c *MediaCache) Get(url string) ([]byte, string, bool) {
+	hash, err := ioutil.ReadFile(c.path("urls", MediaHash([]byte(url))))
+	if err != nil || !isMediaHash(string(hash)) {
+		return nil, "", false
+	}
+
+	data, err := c.Object(string(hash))
+	if err != nil || MediaHash(data) != string(hash) {
+		return nil, "", false
+	}
+
+	return data, string(hash), true
+}
+
+// Object returns the cached media with given hash
+func (c *MediaCache) Object(hash string) ([]byte, error) {
+	if !isMediaHash(hash) {
+		return nil, fmt.Errorf("invalid media hash %q", hash)
+	}
+	return ioutil.ReadFile(c.path("objects", hash))
+}
+
+// Put stores the media downloaded from url and returns its hash
+func (c *MediaCache) Put(url string, data []byte) (string, error) {
+	hash := MediaHash(data)
+
+	objectPath := c.path("objects", hash)
+	if _, err := os.Stat(objectPath); os.IsNotExist(err) {
+		if err := writeFileAtomic(objectPath, data); err != nil {
+			return "", err
+		}
+	}
+
+	if err := writeFileAtomic(c.path("urls", MediaHash([]byte(url))), []byte(hash)); err != nil {
+		return "", err
+	}
+
+	return hash, nil
+}
+
+// Labels returns the cached output of labeler for media with given hash
+func (c *MediaCache) Labels(hash string, labeler string) (models.Labels, bool) {
+	if !isMediaHash(hash) {
+		return nil, false
+	}
+
+	data, err := ioutil.ReadFile(c.path(filepath.Join("labels", labeler), hash))
+	if err != nil {
+		return nil, false
+	}
+
+	var labels models.Labels
+	if err := json.Unmarshal(data, &labels); err != nil {
+		return nil, false
+	}
+
+	return labels, true
+}
+
+// PutLabels stores the output of labeler for media with given hash
+func (c *MediaCache) PutLabels(hash string, labeler string, labels models.Labels) error {
+	if labels == nil {
+		labels = models.Labels{}
+	}
+
+	data, err := json.Marshal(labels)
+	if err != nil {
+		return err
+	}
+
+	return writeFileAtomic(c.path(filepath.Join("labels", labeler), hash), data)
+}
+
+// path returns where key is kept in given section, fanned out by the
+// first two characters of key. Keys are hashes, checked by the callers
+// reading them from disk.
+func (c *MediaCache) path(section string, key string) string {
+	fanout := key
+	if len(key) > 2 {
+		fanout = key[:2]
+	}
+	return filepath.Join(c.Dir, section, fanout, key)
+}
+
+// writeFileAtomic writes data to a temporary file and renames it over path
+// so readers never see partial files
+func writeFileAtomic(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	tmp, err := ioutil.TempFile(filepath.Dir(path), "."+strings.TrimPrefix(filepath.Base(path), ".")+".tmp")
+	if err != nil {
+		return err
+	}
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
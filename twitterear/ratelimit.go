@@ -0,0 +1,137 @@
+package twitterear
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Endpoints with their own rate limit window
+const (
+	EndpointSearch       = "search/tweets"
+	EndpointUserTimeline = "statuses/user_timeline"
+	EndpointLookup       = "statuses/lookup"
+)
+
+// rateLimitWindow is used when a limited response carries no reset header
+const rateLimitWindow = 15 * time.Minute
+
+// maxRateLimitRetries is how many times a call answered with 429 is retried
+const maxRateLimitRetries = 3
+
+// Limiter is the rate limiter shared by all Twitter API calls
+var Limiter = NewRateLimiter()
+
+// Budget is the rate limit state of an endpoint as last reported by Twitter
+type Budget struct {
+	Limit     int       `json:"limit"`
+	Remaining int       `json:"remaining"`
+	Reset     time.Time `json:"reset"`
+}
+
+// RateLimiter keeps per endpoint budgets read from x-rate-limit headers and
+// holds calls back until their window resets once a budget is spent
+type RateLimiter struct {
+	mu      sync.Mutex
+	budgets map[string]*Budget
+}
+
+// NewRateLimiter creates an empty RateLimiter
+func NewRateLimiter() *RateLimiter {
+	return &RateLimiter{
+		budgets: map[string]*Budget{},
+	}
+}
+
+// Do waits for budget on endpoint, performs call and records the budget
+// reported by its response. Calls answered with 429 are retried after the
+// window resets.
+func (r *RateLimiter) Do(endpoint string, call func() (*http.Response, error)) error {
+	for attempt := 0; ; attempt++ {
+		r.Wait(endpoint)
+
+		resp, err := call()
+		r.Update(endpoint, resp)
+
+		if resp != nil && resp.StatusCode == http.StatusTooManyRequests && attempt < maxRateLimitRetries {
+			continue
+		}
+		return err
+	}
+}
+
+// Wait blocks until endpoint has budget left and reserves one call of it
+func (r *RateLimiter) Wait(endpoint string) {
+	for {
+		r.mu.Lock()
+		budget, ok := r.budgets[endpoint]
+		if !ok || budget.Remaining > 0 || !time.Now().Before(budget.Reset) {
+			if ok && budget.Remaining > 0 {
+				budget.Remaining--
+			}
+			r.mu.Unlock()
+			return
+		}
+		wait := time.Until(budget.Reset) + time.Second
+		r.mu.Unlock()
+
+		fmt.Println("Rate limit of", endpoint, "spent, waiting", wait.Round(time.Second))
+		time.Sleep(wait)
+	}
+}
+
+// Update records the budget of endpoint from resp headers
+func (r *RateLimiter) Update(endpoint string, resp *http.Response) {
+	if resp == nil {
+		return
+	}
+
+	remaining, remainingErr := strconv.Atoi(resp.Header.Get("x-rate-limit-remaining"))
+	reset, resetErr := strconv.ParseInt(resp.Header.Get("x-rate-limit-reset"), 10, 64)
+	limit, _ := strconv.Atoi(resp.Header.Get("x-rate-limit-limit"))
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if remainingErr == nil && resetErr == nil {
+		r.budgets[endpoint] = &Budget{
+			Limit:     limit,
+			Remaining: remaining,
+			Reset:     time.Unix(reset, 0),
+		}
+		return
+	}
+
+	if resp.StatusCode == http.StatusTooManyRequests {
+		r.budgets[endpoint] = &Budget{
+			Remaining: 0,
+			Reset:     time.Now().Add(rateLimitWindow),
+		}
+	}
+}
+
+// Budget returns the last known budget of endpoint
+func (r *RateLimiter) Budget(endpoint string) (Budget, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	budget, ok := r.budgets[endpoint]
+	if !ok {
+		return Budget{}, false
+	}
+	return *budget, true
+}
+
+// Budgets returns the last known budgets of all endpoints
+func (r *RateLimiter) Budgets() map[string]Budget {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	budgets := map[string]Budget{}
+	for endpoint, budget := range r.budgets {
+		budgets[endpoint] = *budget
+	}
+	return budgets
+}
@@ -0,0 +1,269 @@
+package twitterear
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/dghubble/go-twitter/twitter"
+)
+
+// ErrNotRecorded is returned by ReplaySource for requests it has no
+// recorded response for
+var ErrNotRecorded = errors.New("no recorded response")
+
+// Recording is a recorded API response, one line of a replay file
+type Recording struct {
+	Endpoint   string          `json:"endpoint"`
+	Request    json.RawMessage `json:"request,omitempty"`
+	Response   json.RawMessage `json:"response"`
+	RecordedAt time.Time       `json:"recorded_at"`
+}
+
+// lookupRequest is the recorded request of a lookup
+type lookupRequest struct {
+	IDs    []int64                     `json:"ids"`
+	Params *twitter.StatusLookupParams `json:"params"`
+}
+
+// ReplaySource is a TweetSource answering from recorded responses. Search
+// pages are replayed in recorded order, timelines by user id and lookups
+// from every recorded tweet.
+type ReplaySource struct {
+	mu        sync.Mutex
+	searches  []*twitter.Search
+	timelines map[int64][]twitter.Tweet
+	tweets    map[int64]twitter.Tweet
+}
+
+// NewReplaySource creates a ReplaySource from JSONL recording files
+func NewReplaySource(paths ...string) (*ReplaySource, error) {
+	s := &ReplaySource{
+		timelines: map[int64][]twitter.Tweet{},
+		tweets:    map[int64]twitter.Tweet{},
+	}
+
+	// Tweets seen in search results are only used by lookups when there is
+	// no recorded lookup for them
+	searched := map[int64]twitter.Tweet{}
+
+	for _, path := range paths {
+		file, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+
+		scanner := bufio.NewScanner(file)
+		scanner.Buffer(make([]byte, 1024*1024), 64*1024*1024)
+		line := 0
+		for scanner.Scan() {
+			line++
+			if len(scanner.Bytes()) == 0 {
+				continue
+			}
+
+			if err := s.add(scanner.Bytes(), searched); err != nil {
+				file.Close()
+				return nil, fmt.Errorf("%s:%d: %v", path, line, err)
+			}
+		}
+		file.Close()
+
+		if err := scanner.Err(); err != nil {
+			return nil, err
+		}
+	}
+
+	for id, tweet := range searched {
+		if _, ok := s.tweets[id]; !ok {
+			s.tweets[id] = tweet
+		}
+	}
+
+	return s, nil
+}
+
+// add indexes a single recording line
+func (s *ReplaySource) add(data []byte, searched map[int64]twitter.Tweet) error {
+	var recording Recording
+	if err := json.Unmarshal(data, &recording); err != nil {
+		return err
+	}
+
+	switch recording.Endpoint {
+	case EndpointSearch:
+		search := &twitter.Search{}
+		if err := json.Unmarshal(recording.Response, search); err != nil {
+			return err
+		}
+		s.searches = append(s.searches, search)
+		for _, tweet := range search.Statuses {
+			searched[tweet.ID] = tweet
+		}
+	case EndpointUserTimeline:
+		params := &twitter.UserTimelineParams{}
+		if err := json.Unmarshal(recording.Request, params); err != nil {
+			return err
+		}
+		var timeline []twitter.Tweet
+		if err := json.Unmarshal(recording.Response, &timeline); err != nil {
+			return err
+		}
+		s.timelines[params.UserID] = timeline
+	case EndpointLookup:
+		var tweets []twitter.Tweet
+		if err := json.Unmarshal(recording.Response, &tweets); err != nil {
+			return err
+		}
+		for _, tweet := range tweets {
+			s.tweets[tweet.ID] = tweet
+		}
+	default:
+		return fmt.Errorf("unknown endpoint %q", recording.Endpoint)
+	}
+
+	return nil
+}
+
+// Search returns the next recorded search page, or an empty page once
+// all of them were replayed
+func (s *ReplaySource) Search(params *twitter.SearchTweetParams) (*twitter.Search, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.searches) == 0 {
+		return &twitter.Search{}, nil
+	}
+
+	search := s.searches[0]
+	s.searches = s.searches[1:]
+	return search, nil
+}
+
+// UserTimeline returns the recorded timeline of the requested user, from
+// max_id down
+func (s *ReplaySource) UserTimeline(params *twitter.UserTimelineParams) ([]twitter.Tweet, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	timeline, ok := s.timelines[params.UserID]
+	if !ok {
+		return nil, ErrNotRecorded
+	}
+
+	// Page through the recording the way max_id pages through a timeline
+	var page []twitter.Tweet
+	for _, tweet := range timeline {
+		if params.MaxID != 0 && tweet.ID > params.MaxID {
+			continue
+		}
+		if params.Count != 0 && len(page) == params.Count {
+			break
+		}
+		page = append(page, tweet)
+	}
+	return page, nil
+}
+
+// Lookup returns the recorded tweets among ids
+func (s *ReplaySource) Lookup(ids []int64, params *twitter.StatusLookupParams) ([]twitter.Tweet, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var tweets []twitter.Tweet
+	for _, id := range ids {
+		if tweet, ok := s.tweets[id]; ok {
+			tweets = append(tweets, tweet)
+		}
+	}
+	return tweets, nil
+}
+
+// RecordingSource is a TweetSource appending every response of the wrapped
+// source to a JSONL file ReplaySource can read
+type RecordingSource struct {
+	Source TweetSource
+
+	mu      sync.Mutex
+	file    *os.File
+	encoder *json.Encoder
+}
+
+// NewRecordingSource creates a RecordingSource appending to path
+func NewRecordingSource(source TweetSource, path string) (*RecordingSource, error) {
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, err
+	}
+
+	return &RecordingSource{
+		Source:  source,
+		file:    file,
+		encoder: json.NewEncoder(file),
+	}, nil
+}
+
+// Close closes the recording file
+func (s *RecordingSource) Close() error {
+	return s.file.Close()
+}
+
+// Search records and returns a page of search results
+func (s *RecordingSource) Search(params *twitter.SearchTweetParams) (*twitter.Search, error) {
+	search, err := s.Source.Search(params)
+	if err == nil {
+		s.record(EndpointSearch, params, search)
+	}
+	return search, err
+}
+
+// UserTimeline records and returns tweets of a user timeline
+func (s *RecordingSource) UserTimeline(params *twitter.UserTimelineParams) ([]twitter.Tweet, error) {
+	timeline, err := s.Source.UserTimeline(params)
+	if err == nil {
+		s.record(EndpointUserTimeline, params, timeline)
+	}
+	return timeline, err
+}
+
+// Lookup records and returns the tweets with given ids which still exist
+func (s *RecordingSource) Lookup(ids []int64, params *twitter.StatusLookupParams) ([]twitter.Tweet, error) {
+	tweets, err := s.Source.Lookup(ids, params)
+	if err == nil {
+		s.record(EndpointLookup, lookupRequest{IDs: ids, Params: params}, tweets)
+	}
+	return tweets, err
+}
+
+// record appends a recording, failures only cost the fixture so they are
+// reported and otherwise ignored
+func (s *RecordingSource) record(endpoint string, request interface{}, response interface{}) {
+	requestData, err := json.Marshal(request)
+	if err != nil {
+		fmt.Println("Error recording request ", err)
+		return
+	}
+	responseData, err := json.Marshal(response)
+	if err != nil {
+		fmt.Println("Error recording response ", err)
+		return
+	}
+
+	recording := Recording{
+		Endpoint:   endpoint,
+		Request:    requestData,
+		Response:   responseData,
+		RecordedAt: time.Now(),
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.encoder.Encode(recording); err != nil {
+		fmt.Println("Error recording response ", err)
+	}
+}
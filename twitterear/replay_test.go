@@ -0,0 +1,207 @@
+package twitterear
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/dghubble/go-twitter/twitter"
+)
+
+// testUser is the author of the tweets of the recordings
+var testUser = &twitter.User{
+	ID:             42,
+	IDStr:          "42",
+	CreatedAt:      "Mon Jan 02 15:04:05 +0000 2017",
+	FollowersCount: 1200,
+	FriendsCount:   300,
+	StatusesCount:  5000,
+}
+
+// testTweet is a tweet of testUser posted age ago
+func testTweet(id int64, text string, favorites int, retweets int, age time.Duration) twitter.Tweet {
+	return twitter.Tweet{
+		ID:            id,
+		IDStr:         strconv.FormatInt(id, 10),
+		CreatedAt:     time.Now().Add(-age).UTC().Format(time.RubyDate),
+		FullText:      text,
+		Lang:          "en",
+		FavoriteCount: favorites,
+		RetweetCount:  retweets,
+		User:          testUser,
+	}
+}
+
+// testSearch is a recorded search page, more tells whether another page
+// follows it
+func testSearch(more bool, tweets ...twitter.Tweet) Recording {
+	search := &twitter.Search{
+		Statuses: tweets,
+		Metadata: &twitter.SearchMetadata{},
+	}
+	if more {
+		search.Metadata.NextResults = "?max_id=1"
+	}
+	return testRecording(EndpointSearch, &twitter.SearchTweetParams{}, search)
+}
+
+// testTimeline is the recorded timeline of testUser
+func testTimeline(tweets ...twitter.Tweet) Recording {
+	return testRecording(EndpointUserTimeline, &twitter.UserTimelineParams{UserID: testUser.ID}, tweets)
+}
+
+func testRecording(endpoint string, request interface{}, response interface{}) Recording {
+	requestData, _ := json.Marshal(request)
+	responseData, _ := json.Marshal(response)
+	return Recording{
+		Endpoint:   endpoint,
+		Request:    requestData,
+		Response:   responseData,
+		RecordedAt: time.Now(),
+	}
+}
+
+// writeRecordings writes recordings to a JSONL file in dir
+func writeRecordings(t *testing.T, dir string, recordings ...Recording) string {
+	path := filepath.Join(dir, "recording.jsonl")
+	file, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	encoder := json.NewEncoder(file)
+	for _, recording := range recordings {
+		if err := encoder.Encode(recording); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return path
+}
+
+// readRecordings reads the recordings of a JSONL file
+func readRecordings(t *testing.T, path string) []Recording {
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	var recordings []Recording
+	decoder := json.NewDecoder(file)
+	for decoder.More() {
+		var recording Recording
+		if err := decoder.Decode(&recording); err != nil {
+			t.Fatal(err)
+		}
+		recordings = append(recordings, recording)
+	}
+	return recordings
+}
+
+func tempDir(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "twitterear")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+func TestReplaySource(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+
+	first := testTweet(300, "first page", 5, 1, 72*time.Hour)
+	second := testTweet(200, "second page", 7, 0, 72*time.Hour)
+	path := writeRecordings(t, dir,
+		testSearch(true, first),
+		testSearch(false, second),
+		testTimeline(
+			testTweet(150, "older", 4, 0, 96*time.Hour),
+			testTweet(120, "oldest", 2, 0, 120*time.Hour),
+		),
+	)
+
+	source, err := NewReplaySource(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, want := range []int64{first.ID, second.ID} {
+		search, err := source.Search(&twitter.SearchTweetParams{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(search.Statuses) != 1 || search.Statuses[0].ID != want {
+			t.Errorf("search page = %v, want tweet %d", search.Statuses, want)
+		}
+	}
+	if search, _ := source.Search(&twitter.SearchTweetParams{}); len(search.Statuses) != 0 {
+		t.Errorf("replayed past the recording: %v", search.Statuses)
+	}
+
+	timeline, err := source.UserTimeline(&twitter.UserTimelineParams{UserID: testUser.ID, MaxID: 149, Count: 5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(timeline) != 1 || timeline[0].ID != 120 {
+		t.Errorf("timeline below max_id = %v, want tweet 120", timeline)
+	}
+	if _, err := source.UserTimeline(&twitter.UserTimelineParams{UserID: 7}); err != ErrNotRecorded {
+		t.Errorf("unrecorded timeline err = %v, want ErrNotRecorded", err)
+	}
+
+	// Search results answer lookups when no lookup was recorded
+	tweets, err := source.Lookup([]int64{first.ID, 999}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tweets) != 1 || tweets[0].ID != first.ID {
+		t.Errorf("lookup = %v, want tweet %d", tweets, first.ID)
+	}
+}
+
+func TestRecordingSourceReplays(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+
+	tweet := testTweet(300, "recorded", 5, 1, 72*time.Hour)
+	source, err := NewReplaySource(writeRecordings(t, dir, testSearch(false, tweet)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(dir, "rerecorded.jsonl")
+	recorder, err := NewRecordingSource(source, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := recorder.Search(&twitter.SearchTweetParams{Query: "foo"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := recorder.Lookup([]int64{tweet.ID}, nil); err != nil {
+		t.Fatal(err)
+	}
+	recorder.Close()
+
+	recordings := readRecordings(t, path)
+	if len(recordings) != 2 || recordings[0].Endpoint != EndpointSearch || recordings[1].Endpoint != EndpointLookup {
+		t.Fatalf("recorded %v, want a search and a lookup", recordings)
+	}
+
+	replayed, err := NewReplaySource(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	search, err := replayed.Search(&twitter.SearchTweetParams{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(search.Statuses) != 1 || search.Statuses[0].FullText != tweet.FullText {
+		t.Errorf("replayed search = %v, want %q", search.Statuses, tweet.FullText)
+	}
+}
@@ -0,0 +1,100 @@
+package twitterear
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/dghubble/go-twitter/twitter"
+	"github.com/thebigear/models"
+)
+
+// Search result types
+const (
+	ResultTypeMixed   = "mixed"
+	ResultTypePopular = "popular"
+	ResultTypeRecent  = "recent"
+)
+
+// GetTweetsFromSearchApi pages through search results of key of given
+// result type posted inside window in the language of checkpoint, resuming
+// from and advancing it. An empty language searches all languages.
+func GetTweetsFromSearchApi(source TweetSource, key *string, count *int, total *int, resultType string, window SearchWindow, checkpoint *models.SearchCheckpoint) []twitter.Tweet {
+
+	now := time.Now()
+
+	ie := true
+	rpp := count
+	// Favorite thresholds are inclusion rules, so runs record what they drop
+	query := fmt.Sprintf("%s AND -filter:retweets AND -filter:replies", *key)
+
+	fmt.Println(query)
+
+	if resultType == "" {
+		resultType = ResultTypeMixed
+	}
+	params := &twitter.SearchTweetParams{
+		Query:           query,
+		Lang:            checkpoint.Lang,
+		IncludeEntities: &ie,
+		TweetMode:       "extended",
+		ResultType:      resultType,
+		Count:           *rpp,
+		SinceID:         checkpoint.SinceID,
+		MaxID:           checkpoint.MaxID,
+	}
+	window.apply(params, now)
+
+	var tweets []twitter.Tweet
+	newestID := checkpoint.NewestID
+	exhausted := false
+
+	// Page backwards through max_id until the window down to since_id is
+	// exhausted or we have collected enough
+	for len(tweets) < *total {
+		if remaining := *total - len(tweets); remaining < params.Count {
+			params.Count = remaining
+		}
+
+		//Search Tweets
+		search, err := source.Search(params)
+		if err != nil {
+			fmt.Println("Search failed: ", err)
+			break
+		}
+
+		for _, tweet := range search.Statuses {
+			if params.MaxID == 0 || tweet.ID <= params.MaxID {
+				params.MaxID = tweet.ID - 1
+			}
+
+			// Tweets too young for the window are left for a later run,
+			// which they stay above the checkpoint for
+			if !window.Contains(tweet, now) {
+				continue
+			}
+			if tweet.ID > newestID {
+				newestID = tweet.ID
+			}
+			tweets = append(tweets, tweet)
+		}
+
+		if len(search.Statuses) == 0 || search.Metadata == nil || search.Metadata.NextResults == "" {
+			exhausted = true
+			break
+		}
+	}
+
+	if exhausted {
+		if newestID > checkpoint.SinceID {
+			checkpoint.SinceID = newestID
+		}
+		checkpoint.MaxID = 0
+		checkpoint.NewestID = 0
+	} else {
+		checkpoint.MaxID = params.MaxID
+		checkpoint.NewestID = newestID
+	}
+
+	return tweets
+
+}
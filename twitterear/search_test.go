@@ -0,0 +1,117 @@
+package twitterear
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/dghubble/go-twitter/twitter"
+	"github.com/thebigear/models"
+)
+
+// searchRecorded replays recordings through a RecordingSource and returns
+// the collected tweets along with the recorded search requests
+func searchRecorded(t *testing.T, total int, window SearchWindow, checkpoint *models.SearchCheckpoint, recordings ...Recording) ([]twitter.Tweet, []*twitter.SearchTweetParams) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+
+	source, err := NewReplaySource(writeRecordings(t, dir, recordings...))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(dir, "requests.jsonl")
+	recorder, err := NewRecordingSource(source, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	key := "foo"
+	count := 2
+	tweets := GetTweetsFromSearchApi(recorder, &key, &count, &total, ResultTypeMixed, window, checkpoint)
+	recorder.Close()
+
+	var requests []*twitter.SearchTweetParams
+	for _, recording := range readRecordings(t, path) {
+		params := &twitter.SearchTweetParams{}
+		if err := json.Unmarshal(recording.Request, params); err != nil {
+			t.Fatal(err)
+		}
+		requests = append(requests, params)
+	}
+
+	return tweets, requests
+}
+
+func TestSearchPagesUntilExhausted(t *testing.T) {
+	checkpoint := &models.SearchCheckpoint{Key: "foo", Lang: "en", SinceID: 100}
+	tweets, requests := searchRecorded(t, 10, SearchWindow{}, checkpoint,
+		testSearch(true, testTweet(500, "a", 3, 0, time.Hour), testTweet(400, "b", 3, 0, time.Hour)),
+		testSearch(false, testTweet(300, "c", 3, 0, time.Hour)),
+	)
+
+	if len(tweets) != 3 {
+		t.Fatalf("collected %d tweets, want 3", len(tweets))
+	}
+	if len(requests) != 2 {
+		t.Fatalf("searched %d pages, want 2", len(requests))
+	}
+	if requests[0].SinceID != 100 || requests[0].MaxID != 0 || requests[0].Lang != "en" {
+		t.Errorf("first request = %+v, want since_id 100 in en", requests[0])
+	}
+	if requests[1].MaxID != 399 {
+		t.Errorf("second request max_id = %d, want 399", requests[1].MaxID)
+	}
+
+	// An exhausted window moves since_id to the newest tweet
+	if checkpoint.SinceID != 500 || checkpoint.MaxID != 0 || checkpoint.NewestID != 0 {
+		t.Errorf("checkpoint = %+v, want since_id 500 and no open window", checkpoint)
+	}
+}
+
+func TestSearchResumesUnfinishedWindow(t *testing.T) {
+	checkpoint := &models.SearchCheckpoint{Key: "foo", Lang: "en", SinceID: 100}
+	tweets, _ := searchRecorded(t, 2, SearchWindow{}, checkpoint,
+		testSearch(true, testTweet(500, "a", 3, 0, time.Hour), testTweet(400, "b", 3, 0, time.Hour)),
+		testSearch(false, testTweet(300, "c", 3, 0, time.Hour)),
+	)
+
+	if len(tweets) != 2 {
+		t.Fatalf("collected %d tweets, want the total of 2", len(tweets))
+	}
+	// The window is left open below the last tweet, since_id waits for it
+	// to be exhausted
+	if checkpoint.SinceID != 100 || checkpoint.MaxID != 399 || checkpoint.NewestID != 500 {
+		t.Errorf("checkpoint = %+v, want since_id 100, max_id 399 and newest_id 500", checkpoint)
+	}
+
+	tweets, requests := searchRecorded(t, 10, SearchWindow{}, checkpoint,
+		testSearch(false, testTweet(300, "c", 3, 0, time.Hour)),
+	)
+	if len(tweets) != 1 || requests[0].MaxID != 399 {
+		t.Fatalf("resumed with max_id %d and %d tweets, want 399 and 1", requests[0].MaxID, len(tweets))
+	}
+	if checkpoint.SinceID != 500 || checkpoint.MaxID != 0 || checkpoint.NewestID != 0 {
+		t.Errorf("checkpoint = %+v, want since_id 500 and no open window", checkpoint)
+	}
+}
+
+func TestSearchLeavesYoungTweetsForLater(t *testing.T) {
+	checkpoint := &models.SearchCheckpoint{Key: "foo"}
+	tweets, requests := searchRecorded(t, 10, SearchWindow{MinAge: 48 * time.Hour}, checkpoint,
+		testSearch(false, testTweet(500, "young", 3, 0, time.Hour), testTweet(400, "old", 3, 0, 72*time.Hour)),
+	)
+
+	if len(tweets) != 1 || tweets[0].ID != 400 {
+		t.Fatalf("collected %v, want only the old tweet", tweets)
+	}
+	if requests[0].Until == "" {
+		t.Errorf("request has no until operator")
+	}
+	// Young tweets stay above since_id so a later run collects them
+	if checkpoint.SinceID != 400 {
+		t.Errorf("checkpoint since_id = %d, want 400", checkpoint.SinceID)
+	}
+}
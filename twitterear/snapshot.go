@@ -0,0 +1,252 @@
+package twitterear
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dghubble/go-twitter/twitter"
+	"github.com/thebigear/database"
+	"github.com/thebigear/models"
+	"github.com/thebigear/utils"
+)
+
+// lookupBatchSize is the maximum number of ids statuses/lookup accepts
+const lookupBatchSize = 100
+
+// pendingPageSize is how many tracked expressions are read at a time
+const pendingPageSize = 1000
+
+// DefaultSnapshotSchedule is the decaying schedule an expression is
+// re-sampled on, relative to the time its tweet was posted
+var DefaultSnapshotSchedule = []time.Duration{
+	time.Hour,
+	6 * time.Hour,
+	24 * time.Hour,
+	3 * 24 * time.Hour,
+	7 * 24 * time.Hour,
+}
+
+// SnapshotTracker re-samples interaction counts of stored expressions
+type SnapshotTracker struct {
+	Source   TweetSource
+	Schedule []time.Duration
+	// MaturityAge is the age at which the snapshot of an immature
+	// expression becomes its interaction count
+	MaturityAge time.Duration
+}
+
+// NewSnapshotTracker creates a SnapshotTracker, falling back to
+// DefaultSnapshotSchedule when no schedule is given. Expressions mature at
+// MATURITY_AGE.
+func NewSnapshotTracker(source TweetSource, schedule []time.Duration) *SnapshotTracker {
+	if len(schedule) == 0 {
+		schedule = DefaultSnapshotSchedule
+	}
+
+	return &SnapshotTracker{
+		Source:      source,
+		Schedule:    schedule,
+		MaturityAge: utils.GetEnvDurationOrDefault("MATURITY_AGE", DefaultMaturityAge),
+	}
+}
+
+// Validate checks that expressions can mature, the last step of the
+// schedule must be at least MaturityAge
+func (t *SnapshotTracker) Validate() error {
+	if len(t.Schedule) == 0 {
+		return fmt.Errorf("the snapshot schedule is empty")
+	}
+
+	last := t.Schedule[len(t.Schedule)-1]
+	if t.MaturityAge > last {
+		return fmt.Errorf("MATURITY_AGE %s is past the last snapshot step %s, immature expressions would never mature", t.MaturityAge, last)
+	}
+	return nil
+}
+
+// ParseSchedule parses comma separated durations like "1h,6h,1d,3d,7d"
+func ParseSchedule(value string) ([]time.Duration, error) {
+	var schedule []time.Duration
+
+	for _, element := range strings.Split(value, ",") {
+		element = strings.TrimSpace(element)
+		if element == "" {
+			continue
+		}
+
+		if strings.HasSuffix(element, "d") {
+			days, err := strconv.Atoi(strings.TrimSuffix(element, "d"))
+			if err != nil {
+				return nil, fmt.Errorf("invalid schedule step %q", element)
+			}
+			schedule = append(schedule, time.Duration(days)*24*time.Hour)
+			continue
+		}
+
+		duration, err := time.ParseDuration(element)
+		if err != nil {
+			return nil, fmt.Errorf("invalid schedule step %q", element)
+		}
+		schedule = append(schedule, duration)
+	}
+
+	return schedule, nil
+}
+
+// Run takes a snapshot of every expression whose next step is due and
+// returns the number of snapshots taken
+func (t *SnapshotTracker) Run() (int, error) {
+	now := time.Now()
+
+	due, err := t.dueExpressions(now)
+	if err != nil {
+		return 0, err
+	}
+
+	taken := 0
+	for start := 0; start < len(due); start += lookupBatchSize {
+		end := start + lookupBatchSize
+		if end > len(due) {
+			end = len(due)
+		}
+
+		n, err := t.sample(due[start:end], now)
+		taken += n
+		if err != nil {
+			return taken, err
+		}
+	}
+
+	return taken, nil
+}
+
+// dueExpressions returns expressions whose next snapshot is due at now
+func (t *SnapshotTracker) dueExpressions(now time.Time) ([]models.Expression, error) {
+	untracked := database.Query{}
+	untracked["snapshot_stage"] = database.Query{"$exists": false}
+
+	pending := database.Query{}
+	pending["snapshot_stage"] = database.Query{"$lt": len(t.Schedule)}
+
+	query := database.Query{}
+	query["deleted_at"] = nil
+	query["$or"] = []database.Query{untracked, pending}
+
+	paginationParams := database.PaginationParamsForContext("", strconv.Itoa(pendingPageSize), "_id")
+
+	// Read every pending expression, the due ones can be anywhere
+	var due []models.Expression
+	for {
+		expressions, err := models.ListExpressions(query, paginationParams)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, expression := range *expressions {
+			stage := snapshotStage(expression)
+			if stage < len(t.Schedule) && !now.Before(trackedFrom(expression).Add(t.Schedule[stage])) {
+				due = append(due, expression)
+			}
+		}
+
+		if len(*expressions) < pendingPageSize {
+			return due, nil
+		}
+		paginationParams.Page++
+	}
+}
+
+// sample looks up fresh counts for a batch of expressions and stores a
+// snapshot for each of them
+func (t *SnapshotTracker) sample(batch []models.Expression, now time.Time) (int, error) {
+	ids := make([]int64, len(batch))
+	for index, expression := range batch {
+		ids[index] = expression.PostID
+	}
+
+	params := &twitter.StatusLookupParams{
+		TweetMode: "extended",
+	}
+
+	tweets, err := t.Source.Lookup(ids, params)
+	if err != nil {
+		return 0, err
+	}
+
+	found := map[int64]twitter.Tweet{}
+	for _, tweet := range tweets {
+		found[tweet.ID] = tweet
+	}
+
+	taken := 0
+	for _, expression := range batch {
+		stage := snapshotStage(expression)
+
+		tweet, ok := found[expression.PostID]
+		if ok {
+			interactions := TweetInteractions(tweet, now)
+			snapshot := &models.Snapshot{
+				ExpressionToken:  expression.URLToken,
+				PostID:           expression.PostID,
+				Stage:            stage,
+				FavoriteCount:    interactions.FavoriteCount,
+				RetweetCount:     interactions.RetweetCount,
+				QuoteCount:       interactions.QuoteCount,
+				ReplyCount:       interactions.ReplyCount,
+				TotalInteraction: interactions.Total(),
+				SampledAt:        now,
+				ObservationAge:   interactions.ObservationAge,
+			}
+			if _, err := snapshot.Create(); err != nil {
+				return taken, err
+			}
+			taken++
+
+			// Tweets collected fresh get their label once they are old
+			// enough
+			age, known := interactions.Age()
+			if expression.Mature != nil && !*expression.Mature && known && age >= t.MaturityAge {
+				total := interactions.Total()
+				mature := true
+				expression.Interactions = interactions
+				expression.TotalInteraction = &total
+				expression.Mature = &mature
+			}
+
+			// Skip every step that has already elapsed so a late run
+			// doesn't take several snapshots back to back
+			for stage < len(t.Schedule) && !now.Before(trackedFrom(expression).Add(t.Schedule[stage])) {
+				stage++
+			}
+		} else {
+			// Deleted or protected tweets are not returned by lookup
+			fmt.Println("Tweet is gone, stop tracking:", expression.PostID)
+			stage = len(t.Schedule)
+		}
+
+		expression.SnapshotStage = &stage
+		if _, err := expression.Update(); err != nil {
+			return taken, err
+		}
+	}
+
+	return taken, nil
+}
+
+// trackedFrom is the time the schedule of expression is relative to, its
+// posting time or, for expressions stored without one, its collection time
+func trackedFrom(expression models.Expression) time.Time {
+	if expression.PostedAt.IsZero() {
+		return expression.CreatedAt
+	}
+	return expression.PostedAt
+}
+
+func snapshotStage(expression models.Expression) int {
+	if expression.SnapshotStage == nil {
+		return 0
+	}
+	return *expression.SnapshotStage
+}
@@ -0,0 +1,28 @@
+package twitterear
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSnapshotTrackerValidate(t *testing.T) {
+	tests := []struct {
+		schedule    []time.Duration
+		maturityAge time.Duration
+		err         bool
+	}{
+		{schedule: DefaultSnapshotSchedule, maturityAge: DefaultMaturityAge},
+		{schedule: DefaultSnapshotSchedule, maturityAge: 7 * 24 * time.Hour},
+		{schedule: DefaultSnapshotSchedule, maturityAge: 8 * 24 * time.Hour, err: true},
+		{schedule: []time.Duration{time.Hour, 6 * time.Hour}, maturityAge: DefaultMaturityAge, err: true},
+		{schedule: nil, maturityAge: DefaultMaturityAge, err: true},
+	}
+
+	for _, test := range tests {
+		tracker := &SnapshotTracker{Schedule: test.schedule, MaturityAge: test.maturityAge}
+		err := tracker.Validate()
+		if (err != nil) != test.err {
+			t.Errorf("schedule %v and maturity age %s: err = %v, want an error %v", test.schedule, test.maturityAge, err, test.err)
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package twitterear
+
+import (
+	"net/http"
+
+	"github.com/dghubble/go-twitter/twitter"
+)
+
+// TweetSource provides tweets from the Twitter API or a recording of it
+type TweetSource interface {
+	Search(params *twitter.SearchTweetParams) (*twitter.Search, error)
+	UserTimeline(params *twitter.UserTimelineParams) ([]twitter.Tweet, error)
+	Lookup(ids []int64, params *twitter.StatusLookupParams) ([]twitter.Tweet, error)
+}
+
+// LiveSource is a TweetSource calling the Twitter API through the shared
+// rate limiter
+type LiveSource struct {
+	Client *twitter.Client
+}
+
+// NewLiveSource creates a LiveSource with given client
+func NewLiveSource(client *twitter.Client) *LiveSource {
+	return &LiveSource{
+		Client: client,
+	}
+}
+
+// Search returns a page of search results
+func (s *LiveSource) Search(params *twitter.SearchTweetParams) (*twitter.Search, error) {
+	var search *twitter.Search
+	err := Limiter.Do(EndpointSearch, func() (*http.Response, error) {
+		var resp *http.Response
+		var err error
+		search, resp, err = s.Client.Search.Tweets(params)
+		return resp, err
+	})
+
+	return search, err
+}
+
+// UserTimeline returns tweets of a user timeline
+func (s *LiveSource) UserTimeline(params *twitter.UserTimelineParams) ([]twitter.Tweet, error) {
+	var timeline []twitter.Tweet
+	err := Limiter.Do(EndpointUserTimeline, func() (*http.Response, error) {
+		var resp *http.Response
+		var err error
+		timeline, resp, err = s.Client.Timelines.UserTimeline(params)
+		return resp, err
+	})
+
+	return timeline, err
+}
+
+// Lookup returns the tweets with given ids which still exist
+func (s *LiveSource) Lookup(ids []int64, params *twitter.StatusLookupParams) ([]twitter.Tweet, error) {
+	var tweets []twitter.Tweet
+	err := Limiter.Do(EndpointLookup, func() (*http.Response, error) {
+		var resp *http.Response
+		var err error
+		tweets, resp, err = s.Client.Statuses.Lookup(ids, params)
+		return resp, err
+	})
+
+	return tweets, err
+}
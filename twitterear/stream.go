@@ -0,0 +1,175 @@
+package twitterear
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/dghubble/go-twitter/twitter"
+	"github.com/thebigear/inclusion"
+	"github.com/thebigear/utils"
+)
+
+// DefaultStreamQueueSize is how many streamed tweets wait for enrichment
+// before new ones are dropped, overridden by STREAM_QUEUE_SIZE
+const DefaultStreamQueueSize = 1000
+
+// Rejection reasons of streamed tweets dropped before the inclusion rules
+const (
+	// ReasonQueueFull drops tweets arriving while the enrichment queue is
+	// full
+	ReasonQueueFull = "queue_full"
+	// ReasonRetweet and ReasonReply drop what the search query filters out
+	ReasonRetweet = "retweet"
+	ReasonReply   = "reply"
+)
+
+// TwitterEarConnect streams tweets tracking the comma separated EAR_TRACK
+// keywords in the comma separated EAR_LANGUAGES into expressions. It blocks
+// and returns right away when EAR_TRACK is not set.
+func TwitterEarConnect() {
+	track := SplitKeywords(utils.GetEnvOrDefault("EAR_TRACK", ""))
+	languages := SplitKeywords(utils.GetEnvOrDefault("EAR_LANGUAGES", ""))
+	if len(track) == 0 {
+		fmt.Println("EAR_TRACK is not set, the ear is not listening")
+		return
+	}
+
+	labeler, err := NewImageLabeler("")
+	if err != nil {
+		fmt.Println("Error creating image labeler ", err)
+	}
+
+	client := NewTwitterClient()
+	collector := NewCollector(NewLiveSource(client), labeler)
+	// Streamed tweets are brand new, they have no interactions yet
+	collector.Rules = inclusion.StreamRules
+	collector.Stream(client, track, languages, nil)
+}
+
+// SplitKeywords splits comma separated keywords dropping empty ones
+func SplitKeywords(value string) []string {
+	var keywords []string
+	for _, keyword := range strings.Split(value, ",") {
+		keyword = strings.TrimSpace(keyword)
+		if keyword != "" {
+			keywords = append(keywords, keyword)
+		}
+	}
+	return keywords
+}
+
+// Stream collects tweets tracking keywords in languages, all of them when
+// empty, from the filter stream of client until stop is closed or the
+// stream gives up. Disconnects, stalls and rate limits are retried with
+// backoff by the stream itself. Tweets are enriched and stored by a worker
+// behind a queue of STREAM_QUEUE_SIZE tweets, so timeline and labeler calls
+// never hold up the stream; tweets arriving while it is full are dropped
+// and counted.
+func (c *Collector) Stream(client *twitter.Client, track []string, languages []string, stop <-chan struct{}) {
+	queue := make(chan twitter.Tweet, utils.GetEnvIntOrDefault("STREAM_QUEUE_SIZE", DefaultStreamQueueSize))
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for tweet := range queue {
+			c.collectStreamed(tweet)
+		}
+	}()
+
+	dropped := 0
+	defer func() {
+		close(queue)
+		<-done
+		// Stats belong to the worker until it is done
+		for i := 0; i < dropped; i++ {
+			c.Stats.Reject(ReasonQueueFull)
+		}
+	}()
+
+	if err := c.stream(client, track, languages, queue, &dropped, stop); err != nil {
+		fmt.Println("Stream stopped: ", err)
+	}
+}
+
+// stream consumes the filter stream into queue, counting the tweets it has
+// no room for in dropped, until stop is closed or the stream stops
+// retrying
+func (c *Collector) stream(client *twitter.Client, track []string, languages []string, queue chan<- twitter.Tweet, dropped *int, stop <-chan struct{}) error {
+	sw := true
+	params := &twitter.StreamFilterParams{
+		Track:         track,
+		Language:      languages,
+		StallWarnings: &sw,
+	}
+
+	stream, err := client.Streams.Filter(params)
+	if err != nil {
+		return err
+	}
+	defer stream.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return nil
+		case message, ok := <-stream.Messages:
+			if !ok {
+				return errors.New("stream closed")
+			}
+
+			switch m := message.(type) {
+			case *twitter.Tweet:
+				select {
+				case queue <- *m:
+				default:
+					*dropped++
+				}
+			case *twitter.StallWarning:
+				fmt.Printf("Stream stall warning %s: %s\n", m.Code, m.Message)
+			case *twitter.StreamDisconnect:
+				// The stream reconnects on its own once the connection ends
+				fmt.Printf("Stream disconnect %d: %s\n", m.Code, m.Reason)
+			case error:
+				return m
+			}
+		}
+	}
+}
+
+// collectStreamed rejects retweets and replies like the search query
+// filters them and collects the tweet in its extended shape
+func (c *Collector) collectStreamed(tweet twitter.Tweet) {
+	if tweet.RetweetedStatus != nil {
+		c.Stats.Reject(ReasonRetweet)
+		return
+	}
+	if tweet.InReplyToStatusID != 0 {
+		c.Stats.Reject(ReasonReply)
+		return
+	}
+
+	if _, err := c.Collect(extendedTweet(tweet)); err != nil {
+		fmt.Println("Error storing tweet ", tweet.ID, err)
+	}
+}
+
+// extendedTweet moves the full text and entities of a compatibility mode
+// tweet, as delivered by streams, to where the search API puts them
+func extendedTweet(tweet twitter.Tweet) twitter.Tweet {
+	if tweet.ExtendedTweet == nil {
+		if tweet.FullText == "" {
+			tweet.FullText = tweet.Text
+		}
+		return tweet
+	}
+
+	tweet.FullText = tweet.ExtendedTweet.FullText
+	if tweet.ExtendedTweet.Entities != nil {
+		tweet.Entities = tweet.ExtendedTweet.Entities
+	}
+	if tweet.ExtendedTweet.ExtendedEntities != nil {
+		tweet.ExtendedEntities = tweet.ExtendedTweet.ExtendedEntities
+	}
+
+	return tweet
+}
@@ -0,0 +1,26 @@
+package twitterear
+
+import (
+	"testing"
+	"time"
+
+	"github.com/thebigear/models"
+)
+
+func TestCollectStreamedCountsRetweetsAndReplies(t *testing.T) {
+	collector := &Collector{Stats: models.NewInclusionStats()}
+
+	original := testTweet(100, "original", 0, 0, time.Hour)
+	retweet := testTweet(101, "RT original", 0, 0, time.Minute)
+	retweet.RetweetedStatus = &original
+	reply := testTweet(102, "a reply", 0, 0, time.Minute)
+	reply.InReplyToStatusID = original.ID
+
+	collector.collectStreamed(retweet)
+	collector.collectStreamed(reply)
+
+	stats := collector.Stats
+	if stats.Seen != 2 || stats.Rejected != 2 || stats.Reasons[ReasonRetweet] != 1 || stats.Reasons[ReasonReply] != 1 {
+		t.Errorf("stats = %+v, want a retweet and a reply rejected", stats)
+	}
+}
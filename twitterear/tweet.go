@@ -0,0 +1,76 @@
+package twitterear
+
+import (
+	"time"
+
+	"github.com/dghubble/go-twitter/twitter"
+	"github.com/thebigear/models"
+)
+
+// HasAttachment reports whether tweet has any media
+func HasAttachment(tweet twitter.Tweet) bool {
+
+	return len(TweetMedia(tweet)) > 0
+
+}
+
+// TweetMedia returns every media entity of tweet. Entities only carry the
+// first photo, extended entities carry all photos, videos and GIFs.
+func TweetMedia(tweet twitter.Tweet) []twitter.MediaEntity {
+
+	if tweet.ExtendedEntities != nil && len(tweet.ExtendedEntities.Media) > 0 {
+		return tweet.ExtendedEntities.Media
+	}
+
+	if tweet.Entities != nil {
+		return tweet.Entities.Media
+	}
+
+	return nil
+
+}
+
+// TweetInteractions returns the interaction counters of tweet observed at
+// observedAt. Their age is unknown when the posting time of tweet is.
+func TweetInteractions(tweet twitter.Tweet, observedAt time.Time) *models.Interactions {
+	interactions := &models.Interactions{
+		FavoriteCount: tweet.FavoriteCount,
+		RetweetCount:  tweet.RetweetCount,
+		QuoteCount:    tweet.QuoteCount,
+		ReplyCount:    tweet.ReplyCount,
+		ObservedAt:    observedAt,
+	}
+
+	if postedAt, err := tweet.CreatedAtTime(); err == nil {
+		age := int64(observedAt.Sub(postedAt) / time.Second)
+		interactions.ObservationAge = &age
+	}
+
+	return interactions
+}
+
+// NewMedia describes a media entity, picking the highest bitrate MP4 of
+// videos and GIFs
+func NewMedia(entity twitter.MediaEntity) models.Media {
+	media := models.Media{
+		ID:             entity.ID,
+		Type:           entity.Type,
+		URL:            entity.MediaURLHttps,
+		Width:          entity.Sizes.Large.Width,
+		Height:         entity.Sizes.Large.Height,
+		DurationMillis: entity.VideoInfo.DurationMillis,
+	}
+	if media.URL == "" {
+		media.URL = entity.MediaURL
+	}
+
+	bitrate := -1
+	for _, variant := range entity.VideoInfo.Variants {
+		if variant.ContentType == "video/mp4" && variant.Bitrate > bitrate {
+			bitrate = variant.Bitrate
+			media.VideoURL = variant.URL
+		}
+	}
+
+	return media
+}
@@ -0,0 +1,26 @@
+package twitterear
+
+import (
+	"fmt"
+
+	"github.com/dghubble/go-twitter/twitter"
+	"github.com/dghubble/oauth1"
+	"github.com/thebigear/utils"
+)
+
+// NewTwitterClient creates a Twitter client with credentials from environment
+func NewTwitterClient() *twitter.Client {
+	fmt.Println("Initializing Twitter Connection...")
+
+	consumerKey := utils.GetEnvOrDefault("TWITTER_CONSUMER_KEY", "")
+	consumerSecret := utils.GetEnvOrDefault("TWITTER_CONSUMER_SECRET", "")
+
+	accessToken := utils.GetEnvOrDefault("TWITTER_ACCESS_TOKEN", "")
+	accessSecret := utils.GetEnvOrDefault("TWITTER_ACCESS_SECRET", "")
+
+	config := oauth1.NewConfig(consumerKey, consumerSecret)
+	token := oauth1.NewToken(accessToken, accessSecret)
+	httpClient := config.Client(oauth1.NoContext, token)
+
+	return twitter.NewClient(httpClient)
+}
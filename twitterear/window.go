@@ -0,0 +1,75 @@
+package twitterear
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/dghubble/go-twitter/twitter"
+)
+
+// DefaultMaturityAge is how old a tweet has to be for its interaction
+// count to be considered mature, overridden by MATURITY_AGE
+const DefaultMaturityAge = 48 * time.Hour
+
+// searchDateLayout is the day granularity of the since and until operators
+const searchDateLayout = "2006-01-02"
+
+// SearchWindow bounds the posting time of searched tweets. Search only
+// filters by day, tweets outside the exact bounds are dropped from results.
+type SearchWindow struct {
+	// Since is the earliest posting time, zero for no bound
+	Since time.Time
+	// Until is the latest posting time, exclusive, zero for now
+	Until time.Time
+	// MinAge keeps tweets younger than it out, zero collects fresh tweets
+	MinAge time.Duration
+}
+
+// DefaultSearchWindow collects tweets old enough to be mature
+var DefaultSearchWindow = SearchWindow{MinAge: DefaultMaturityAge}
+
+// ParseWindowTime parses a window bound given as a date or an RFC 3339
+// time, an empty value is no bound
+func ParseWindowTime(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if date, err := time.Parse(searchDateLayout, value); err == nil {
+		return date, nil
+	}
+	moment, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%q is neither a date nor an RFC 3339 time", value)
+	}
+	return moment, nil
+}
+
+// Bounds returns the exact posting time range of the window at now
+func (w SearchWindow) Bounds(now time.Time) (time.Time, time.Time) {
+	until := now.Add(-w.MinAge)
+	if !w.Until.IsZero() && w.Until.Before(until) {
+		until = w.Until
+	}
+	return w.Since, until
+}
+
+// apply sets the day granular since and until operators of params
+func (w SearchWindow) apply(params *twitter.SearchTweetParams, now time.Time) {
+	since, until := w.Bounds(now)
+	if !since.IsZero() {
+		params.Since = since.UTC().Format(searchDateLayout)
+	}
+	// until excludes its day, so ask for the day after the bound
+	params.Until = until.UTC().AddDate(0, 0, 1).Format(searchDateLayout)
+}
+
+// Contains reports whether tweet was posted inside the window at now
+func (w SearchWindow) Contains(tweet twitter.Tweet, now time.Time) bool {
+	postedAt, err := tweet.CreatedAtTime()
+	if err != nil {
+		return false
+	}
+
+	since, until := w.Bounds(now)
+	return !postedAt.Before(since) && postedAt.Before(until)
+}
@@ -1,6 +1,10 @@
 package utils
 
-import "os"
+import (
+	"os"
+	"strconv"
+	"time"
+)
 
 // GetEnvOrDefault gets environment variable with given key
 // If it's not exists returns given default value
@@ -11,3 +15,23 @@ func GetEnvOrDefault(key, def string) string {
 	}
 	return value
 }
+
+// GetEnvIntOrDefault gets integer environment variable with given key
+// If it's not exists or not an integer returns given default value
+func GetEnvIntOrDefault(key string, def int) int {
+	value, err := strconv.Atoi(os.Getenv(key))
+	if err != nil {
+		return def
+	}
+	return value
+}
+
+// GetEnvDurationOrDefault gets duration environment variable with given key
+// If it's not exists or not a duration returns given default value
+func GetEnvDurationOrDefault(key string, def time.Duration) time.Duration {
+	value, err := time.ParseDuration(os.Getenv(key))
+	if err != nil {
+		return def
+	}
+	return value
+}